@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// Usage:
+//
+//	go run . -pgn annotated.pgn                    # use the games' [%eval] commands
+//	go run . -pgn games.pgn -engine stockfish -depth 12
+//	go run . -pgn annotated.pgn -json > report.json
+//
+// Games annotated by ../engine_annotation carry the [%eval] commands this
+// report reads. Without -pgn a few annotated demo games are used.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file to report on")
+	enginePath := flag.String("engine", "", "UCI engine to evaluate the games with instead of their [%eval] commands")
+	depth := flag.Int("depth", 12, "search depth per position when using -engine")
+	moveTime := flag.Duration("movetime", 0, "search time per position when using -engine; overrides -depth")
+	asJSON := flag.Bool("json", false, "write the report as JSON")
+	flag.Parse()
+
+	if *pgnPath == "" {
+		runDemo()
+		return
+	}
+
+	var eval evaluator
+	if *enginePath != "" {
+		limits := uci.CmdGo{Depth: *depth}
+		if *moveTime > 0 {
+			limits = uci.CmdGo{MoveTime: *moveTime}
+		}
+		engine, err := startEngine(*enginePath)
+		if err != nil {
+			log.Fatalf("Error starting engine: %v", err)
+		}
+		defer engine.Close()
+		eval = engineEvaluator(engine, limits)
+	}
+
+	f, err := os.Open(*pgnPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	report := BuildReport(f, eval)
+	if *asJSON {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	report.WriteTable(os.Stdout)
+}
+
+func startEngine(path string) (*uci.Engine, error) {
+	engine, err := uci.New(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Run(uci.CmdUCI, uci.CmdIsReady, uci.CmdUCINewGame); err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return engine, nil
+}
+
+// engineEvaluator returns an evaluator searching each position with limits.
+// UCI scores are from the side to move, so they are turned around for
+// Black.
+func engineEvaluator(engine *uci.Engine, limits uci.CmdGo) evaluator {
+	return func(pos *chess.Position) (Eval, error) {
+		if err := engine.Run(uci.CmdPosition{Position: pos}, limits); err != nil {
+			return Eval{}, err
+		}
+		score := engine.SearchResults().Info.Score
+		e := Eval{CP: score.CP, Mate: score.Mate}
+		if pos.Turn() == chess.Black {
+			e.CP, e.Mate = -e.CP, -e.Mate
+		}
+		return e, nil
+	}
+}
+
+func runDemo() {
+	fmt.Println("=== Accuracy Report Example ===")
+
+	// Example 1: Evaluations from [%eval] commands
+	fmt.Println("\n1. Per Move Loss and Accuracy From Eval Commands")
+	game, err := chess.NewScanner(strings.NewReader(demoPGN)).ParseNext()
+	if err != nil {
+		log.Printf("Error parsing game: %v\n", err)
+		return
+	}
+	evals, err := gameEvals(game, nil)
+	if err != nil {
+		log.Printf("Error reading evaluations: %v\n", err)
+		return
+	}
+	positions := game.Positions()
+	for i, m := range game.Moves() {
+		mover := positions[i].Turn()
+		fmt.Printf("  %-6s %-6s eval %6.2f  loss %4d  win%% %5.1f -> %5.1f  accuracy %5.1f\n",
+			mover.Name(), chess.AlgebraicNotation{}.Encode(positions[i], m),
+			float64(evals[i+1].centipawns(chess.White))/100,
+			max(0, evals[i].centipawns(mover)-evals[i+1].centipawns(mover)),
+			evals[i].winPercent(mover), evals[i+1].winPercent(mover),
+			moveAccuracy(evals[i].winPercent(mover), evals[i+1].winPercent(mover)))
+	}
+
+	// Example 2: Per game and per player table
+	fmt.Println("\n2. Report Table")
+	start := time.Now()
+	report := BuildReport(strings.NewReader(demoPGN), nil)
+	report.WriteTable(os.Stdout)
+	fmt.Printf("(%d games in %v)\n", len(report.Games), time.Since(start).Round(time.Microsecond))
+
+	// Example 3: JSON for other tools
+	fmt.Println("\n3. Report as JSON (players only)")
+	data, err := json.MarshalIndent(report.Players, "", "  ")
+	if err != nil {
+		log.Printf("Error writing JSON: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// The scanner splits games on the Event tag, so every game carries one.
+const demoPGN = `[Event "Club Match"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 {[%eval 0.36]} 1... e5 {[%eval 0.30]} 2. Nf3 {[%eval 0.28]} 2... Nc6
+{[%eval 0.33]} 3. Bb5 {[%eval 0.31]} 3... a6 {[%eval 0.35]} 4. Ba4 {[%eval 0.30]}
+4... Nf6 {[%eval 0.37]} 5. O-O {[%eval 0.29]} 5... Nxe4 {[%eval 0.40]} 6. d4
+{[%eval 0.32]} 6... Be7 $6 {[%eval 0.95]} 7. Re1 {[%eval 0.88]} 7... Nd6 $2
+{[%eval 2.10]} 8. Bxc6 {[%eval 2.05]} 8... dxc6 {[%eval 2.12]} 9. dxe5
+{[%eval 2.00]} 9... Nf5 $4 {[%eval 5.80]} 10. Qxd8+ {[%eval 5.75]} 10... Bxd8
+{[%eval 5.90]} 1-0
+
+[Event "Club Match"]
+[White "Bob"]
+[Black "Alice"]
+[Result "1/2-1/2"]
+
+1. d4 {[%eval 0.25]} 1... Nf6 {[%eval 0.22]} 2. c4 {[%eval 0.30]} 2... e6
+{[%eval 0.28]} 3. Nc3 {[%eval 0.26]} 3... Bb4 {[%eval 0.31]} 4. e3 {[%eval 0.20]}
+4... O-O {[%eval 0.24]} 5. Bd3 {[%eval 0.18]} 5... d5 {[%eval 0.20]} 6. Nf3
+{[%eval 0.15]} 6... c5 {[%eval 0.19]} 7. O-O {[%eval 0.12]} 1/2-1/2
+
+[Event "Club Match"]
+[White "Carol"]
+[Black "Alice"]
+[Result "0-1"]
+
+1. f3 {[%eval -0.60]} 1... e5 {[%eval -0.55]} 2. g4 $4 {[%eval #-1]} 2... Qh4# 0-1
+`
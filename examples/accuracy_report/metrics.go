@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// mateCP is the centipawn value a forced mate counts for. Evaluations are
+// clamped to ±mateCP, so a missed mate is a large but finite loss.
+const mateCP = 1000
+
+// Eval is an evaluation from White's point of view, as written in [%eval]
+// commands.
+type Eval struct {
+	CP   int // centipawns, when Mate is 0
+	Mate int // mate in N moves; positive when White mates
+}
+
+// ParseEval parses the value of an [%eval] command: "0.31", "-1.20", "#3"
+// or "#-2".
+func ParseEval(s string) (Eval, error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "#"); ok {
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return Eval{}, fmt.Errorf("invalid mate score %q", s)
+		}
+		if n == 0 {
+			// "#0" and "#-0" mark a position that is already mate; the
+			// sign is lost, so callers should check the position instead.
+			return Eval{}, fmt.Errorf("ambiguous mate score %q", s)
+		}
+		return Eval{Mate: n}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Eval{}, fmt.Errorf("invalid evaluation %q", s)
+	}
+	return Eval{CP: int(math.Round(f * 100))}, nil
+}
+
+// mateEval returns the evaluation of a checkmated position: the side to
+// move has lost.
+func mateEval(pos *chess.Position) Eval {
+	if pos.Turn() == chess.White {
+		return Eval{CP: -mateCP}
+	}
+	return Eval{CP: mateCP}
+}
+
+// centipawns returns the evaluation for color, with mates clamped to
+// ±mateCP.
+func (e Eval) centipawns(color chess.Color) int {
+	cp := max(-mateCP, min(mateCP, e.CP))
+	switch {
+	case e.Mate > 0:
+		cp = mateCP
+	case e.Mate < 0:
+		cp = -mateCP
+	}
+	if color == chess.Black {
+		return -cp
+	}
+	return cp
+}
+
+// winPercent converts the evaluation into color's chance of winning,
+// from 0 to 100, using the logistic curve Lichess fitted to its games.
+func (e Eval) winPercent(color chess.Color) float64 {
+	cp := float64(e.centipawns(color))
+	return 50 + 50*(2/(1+math.Exp(-0.00368208*cp))-1)
+}
+
+// moveAccuracy rates a move from 0 to 100 by how much of the mover's
+// winning chances it gave away.
+func moveAccuracy(winBefore, winAfter float64) float64 {
+	if winAfter >= winBefore {
+		return 100
+	}
+	acc := 103.1668*math.Exp(-0.04354*(winBefore-winAfter)) - 3.1669
+	return max(0, min(100, acc))
+}
+
+// PlayerGame holds one side's metrics for one game.
+type PlayerGame struct {
+	Moves    int     `json:"moves"`
+	ACPL     float64 `json:"acpl"`
+	Accuracy float64 `json:"accuracy"`
+
+	// totalLoss is kept so player aggregates can weight games by their
+	// number of moves.
+	totalLoss int
+}
+
+// gameMetrics computes both players' metrics from the evaluations of a
+// game's positions: evals[0] before the first move and evals[i] after the
+// i-th. firstMover is the side that played the first move.
+func gameMetrics(evals []Eval, firstMover chess.Color) [2]PlayerGame {
+	var stats [2]PlayerGame
+	if len(evals) < 2 {
+		return stats
+	}
+
+	// Win chances from White's point of view, used to weight moves by how
+	// volatile the game was around them.
+	wins := make([]float64, len(evals))
+	for i, e := range evals {
+		wins[i] = e.winPercent(chess.White)
+	}
+	weights := volatilityWeights(wins)
+
+	var weighted, weightSum, harmonic [2]float64
+	mover := firstMover
+	for i := 1; i < len(evals); i++ {
+		side := colorIndex(mover)
+		loss := max(0, evals[i-1].centipawns(mover)-evals[i].centipawns(mover))
+		acc := moveAccuracy(evals[i-1].winPercent(mover), evals[i].winPercent(mover))
+
+		stats[side].Moves++
+		stats[side].totalLoss += loss
+		weighted[side] += acc * weights[i-1]
+		weightSum[side] += weights[i-1]
+		harmonic[side] += 1 / max(acc, 1)
+		mover = mover.Other()
+	}
+
+	for side := range stats {
+		s := &stats[side]
+		if s.Moves == 0 {
+			continue
+		}
+		s.ACPL = round1(float64(s.totalLoss) / float64(s.Moves))
+		// Like Lichess, average a volatility weighted mean, which stresses
+		// the critical moments, with a harmonic mean, which punishes the
+		// worst moves.
+		s.Accuracy = round1((weighted[side]/weightSum[side] + float64(s.Moves)/harmonic[side]) / 2)
+	}
+	return stats
+}
+
+// volatilityWeights returns a weight per move: the standard deviation of
+// the win chances in a window around it, clamped to [0.5, 12].
+func volatilityWeights(wins []float64) []float64 {
+	moves := len(wins) - 1
+	window := max(2, min(8, moves/10))
+	weights := make([]float64, moves)
+	for i := range weights {
+		start := max(0, min(i, len(wins)-window))
+		weights[i] = max(0.5, min(12, stddev(wins[start:start+window])))
+	}
+	return weights
+}
+
+// round1 rounds x to one decimal, which is all the precision the report
+// needs.
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+func stddev(xs []float64) float64 {
+	var mean float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	var variance float64
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(variance / float64(len(xs)))
+}
+
+// colorIndex maps White to 0 and Black to 1 for indexing per side arrays.
+func colorIndex(c chess.Color) int {
+	if c == chess.Black {
+		return 1
+	}
+	return 0
+}
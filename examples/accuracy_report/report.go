@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// GameReport holds the metrics of one game.
+type GameReport struct {
+	Index  int        `json:"index"`
+	Event  string     `json:"event,omitempty"`
+	White  string     `json:"white"`
+	Black  string     `json:"black"`
+	Result string     `json:"result"`
+	Stats  PlayerPair `json:"stats"`
+}
+
+// PlayerPair holds the metrics of both sides of a game.
+type PlayerPair struct {
+	White PlayerGame `json:"white"`
+	Black PlayerGame `json:"black"`
+}
+
+// PlayerStats aggregates a player's metrics over all their games. ACPL is
+// weighted by moves; Accuracy is the mean of the per game accuracies.
+type PlayerStats struct {
+	Name     string  `json:"name"`
+	Games    int     `json:"games"`
+	Moves    int     `json:"moves"`
+	ACPL     float64 `json:"acpl"`
+	Accuracy float64 `json:"accuracy"`
+
+	totalLoss     int
+	totalAccuracy float64
+}
+
+// Report is the result of analysing a PGN collection.
+type Report struct {
+	Games   []GameReport  `json:"games"`
+	Players []PlayerStats `json:"players"`
+	// Skipped lists games that could not be evaluated, with the reason.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// errNoEvals is returned for games without [%eval] commands when no engine
+// is available to evaluate them.
+var errNoEvals = errors.New("missing [%eval] annotations")
+
+// evaluator evaluates a position from White's point of view.
+type evaluator func(pos *chess.Position) (Eval, error)
+
+// BuildReport computes the metrics of every game read from r. Evaluations
+// come from the games' [%eval] commands, or from eval when it is not nil.
+func BuildReport(r io.Reader, eval evaluator) *Report {
+	report := &Report{}
+	players := map[string]*PlayerStats{}
+
+	scanner := chess.NewScanner(r)
+	for n := 1; scanner.HasNext(); n++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("game %d: %v", n, err))
+			continue
+		}
+		moves := game.Moves()
+		if len(moves) == 0 {
+			continue
+		}
+
+		evals, err := gameEvals(game, eval)
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("game %d: %v", n, err))
+			continue
+		}
+		stats := gameMetrics(evals, moves[0].Parent().Position().Turn())
+
+		gr := GameReport{
+			Index:  n,
+			Event:  game.GetTagPair("Event"),
+			White:  playerName(game.GetTagPair("White")),
+			Black:  playerName(game.GetTagPair("Black")),
+			Result: gameResult(game),
+			Stats:  PlayerPair{White: stats[0], Black: stats[1]},
+		}
+		report.Games = append(report.Games, gr)
+		addGame(players, gr.White, stats[0])
+		addGame(players, gr.Black, stats[1])
+	}
+
+	for _, p := range players {
+		p.ACPL = round1(float64(p.totalLoss) / float64(p.Moves))
+		p.Accuracy = round1(p.totalAccuracy / float64(p.Games))
+		report.Players = append(report.Players, *p)
+	}
+	sort.Slice(report.Players, func(i, j int) bool {
+		return report.Players[i].Name < report.Players[j].Name
+	})
+	return report
+}
+
+func addGame(players map[string]*PlayerStats, name string, g PlayerGame) {
+	if g.Moves == 0 {
+		return
+	}
+	p, ok := players[name]
+	if !ok {
+		p = &PlayerStats{Name: name}
+		players[name] = p
+	}
+	p.Games++
+	p.Moves += g.Moves
+	p.totalLoss += g.totalLoss
+	p.totalAccuracy += g.Accuracy
+}
+
+// gameResult prefers the Result tag, which is set even for games the
+// parser does not see finished, such as agreed draws.
+func gameResult(game *chess.Game) string {
+	if r := game.GetTagPair("Result"); r != "" {
+		return r
+	}
+	return game.Outcome().String()
+}
+
+func playerName(tag string) string {
+	if tag == "" {
+		return "?"
+	}
+	return tag
+}
+
+// gameEvals returns the evaluation of every mainline position of game,
+// starting with the position before the first move.
+func gameEvals(game *chess.Game, eval evaluator) ([]Eval, error) {
+	moves := game.Moves()
+	positions := []*chess.Position{moves[0].Parent().Position()}
+	for _, m := range moves {
+		positions = append(positions, m.Position())
+	}
+
+	evals := make([]Eval, len(positions))
+	for i, pos := range positions {
+		var err error
+		switch {
+		case pos.Status() == chess.Checkmate:
+			evals[i] = mateEval(pos)
+		case pos.Status() == chess.Stalemate:
+			evals[i] = Eval{}
+		case eval != nil:
+			evals[i], err = eval(pos)
+		case i == 0:
+			// Annotations sit on moves, so nothing evaluates the position
+			// before the first move. Count it as equal.
+			evals[i] = Eval{}
+		default:
+			value, ok := moves[i-1].GetCommand("eval")
+			if !ok {
+				return nil, fmt.Errorf("move %d: %w", i, errNoEvals)
+			}
+			evals[i], err = ParseEval(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %w", i, err)
+		}
+	}
+	return evals, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the report as two plain text tables, one row per game
+// and one per player.
+func (r *Report) WriteTable(w io.Writer) {
+	fmt.Fprintf(w, "%-4s %-20s %-20s %-8s %17s %17s\n", "#", "White", "Black", "Result", "White ACPL / Acc", "Black ACPL / Acc")
+	fmt.Fprintln(w, strings.Repeat("-", 91))
+	for _, g := range r.Games {
+		fmt.Fprintf(w, "%-4d %-20s %-20s %-8s %8.1f / %5.1f%% %8.1f / %5.1f%%\n",
+			g.Index, truncate(g.White, 20), truncate(g.Black, 20), g.Result,
+			g.Stats.White.ACPL, g.Stats.White.Accuracy, g.Stats.Black.ACPL, g.Stats.Black.Accuracy)
+	}
+
+	fmt.Fprintf(w, "\n%-24s %6s %6s %8s %9s\n", "Player", "Games", "Moves", "ACPL", "Accuracy")
+	fmt.Fprintln(w, strings.Repeat("-", 57))
+	for _, p := range r.Players {
+		fmt.Fprintf(w, "%-24s %6d %6d %8.1f %8.1f%%\n", truncate(p.Name, 24), p.Games, p.Moves, p.ACPL, p.Accuracy)
+	}
+
+	for _, s := range r.Skipped {
+		fmt.Fprintf(w, "skipped %s\n", s)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// mateCP is the centipawn value a forced mate counts for when computing
+// centipawn loss, so that missing a mate is a large but finite loss.
+const mateCP = 1000
+
+// Eval is an engine evaluation from White's point of view.
+type Eval struct {
+	CP   int // centipawns, when Mate is 0
+	Mate int // mate in N moves; positive when White mates
+	// Over is set for checkmate and stalemate, which are scored without
+	// asking the engine and get no [%eval] command.
+	Over bool
+}
+
+// centipawns returns the evaluation with mates clamped to ±mateCP.
+func (e Eval) centipawns() int {
+	switch {
+	case e.Mate > 0:
+		return mateCP
+	case e.Mate < 0:
+		return -mateCP
+	}
+	return max(-mateCP, min(mateCP, e.CP))
+}
+
+// String formats the evaluation the way [%eval] commands carry it: "0.31",
+// "-1.20", "#3" or "#-2".
+func (e Eval) String() string {
+	if e.Mate != 0 {
+		return fmt.Sprintf("#%d", e.Mate)
+	}
+	return fmt.Sprintf("%.2f", float64(e.CP)/100)
+}
+
+// Thresholds are the centipawn losses from which a move is marked as an
+// inaccuracy ($6), a mistake ($2) or a blunder ($4).
+type Thresholds struct {
+	Inaccuracy int
+	Mistake    int
+	Blunder    int
+}
+
+// DefaultThresholds are the usual 50/100/300 centipawn limits.
+var DefaultThresholds = Thresholds{Inaccuracy: 50, Mistake: 100, Blunder: 300}
+
+// classify returns the NAG and the word for a centipawn loss, or empty
+// strings for a good move.
+func (t Thresholds) classify(loss int) (string, string) {
+	switch {
+	case loss >= t.Blunder:
+		return "$4", "Blunder"
+	case loss >= t.Mistake:
+		return "$2", "Mistake"
+	case loss >= t.Inaccuracy:
+		return "$6", "Inaccuracy"
+	}
+	return "", ""
+}
+
+// Summary counts the errors found in one game, indexed by chess.White and
+// chess.Black.
+type Summary struct {
+	Inaccuracies [3]int
+	Mistakes     [3]int
+	Blunders     [3]int
+}
+
+func (s *Summary) count(color chess.Color, nag string) {
+	switch nag {
+	case "$6":
+		s.Inaccuracies[color]++
+	case "$2":
+		s.Mistakes[color]++
+	case "$4":
+		s.Blunders[color]++
+	}
+}
+
+// Annotator evaluates every mainline position of a game with a UCI engine
+// and writes the results back into the game's moves.
+type Annotator struct {
+	engine *uci.Engine
+	limits uci.CmdGo
+
+	Thresholds Thresholds
+	// Variations inserts the engine's best line as a variation before
+	// every move marked as an inaccuracy, mistake or blunder.
+	Variations bool
+	// PVLength limits the length of inserted variations (0 for no limit).
+	PVLength int
+}
+
+// NewAnnotator starts the engine at path. Every position is searched with
+// limits, which should set a depth or a move time.
+func NewAnnotator(path string, limits uci.CmdGo) (*Annotator, error) {
+	engine, err := uci.New(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Run(uci.CmdUCI, uci.CmdIsReady); err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return &Annotator{
+		engine:     engine,
+		limits:     limits,
+		Thresholds: DefaultThresholds,
+		Variations: true,
+		PVLength:   8,
+	}, nil
+}
+
+// Close stops the engine.
+func (a *Annotator) Close() error {
+	return a.engine.Close()
+}
+
+// Annotate adds an [%eval] command to every mainline move of game and marks
+// inaccuracies, mistakes and blunders with a NAG and a comment naming the
+// engine's choice. NAGs already present in the game are kept.
+func (a *Annotator) Annotate(game *chess.Game) (Summary, error) {
+	var summary Summary
+	moves := game.Moves()
+	if len(moves) == 0 {
+		return summary, nil
+	}
+	if err := a.engine.Run(uci.CmdUCINewGame, uci.CmdIsReady); err != nil {
+		return summary, err
+	}
+
+	// Evaluate the position before every move and the final position.
+	positions := make([]*chess.Position, 0, len(moves)+1)
+	positions = append(positions, moves[0].Parent().Position())
+	for _, m := range moves {
+		positions = append(positions, m.Position())
+	}
+	evals := make([]Eval, len(positions))
+	pvs := make([][]*chess.Move, len(positions))
+	for i, pos := range positions {
+		var err error
+		if evals[i], pvs[i], err = a.evaluate(pos); err != nil {
+			return summary, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+
+	for i, m := range moves {
+		before, after := evals[i], evals[i+1]
+		if !after.Over {
+			m.SetCommand("eval", after.String())
+		}
+
+		pos := positions[i]
+		pv := pvs[i]
+		if len(pv) == 0 || sameMove(pv[0], m) {
+			continue
+		}
+		loss := before.centipawns() - after.centipawns()
+		if pos.Turn() == chess.Black {
+			loss = -loss
+		}
+		nag, word := a.Thresholds.classify(loss)
+		if nag == "" {
+			continue
+		}
+
+		if m.NAG() == "" {
+			m.SetNAG(nag)
+		}
+		summary.count(pos.Turn(), nag)
+		best := chess.AlgebraicNotation{}.Encode(pos, pv[0])
+		addComment(m, fmt.Sprintf("%s. %s was best.", word, best))
+		if a.Variations {
+			if err := a.addVariation(game, m.Parent(), pos, pv); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, nil
+}
+
+// evaluate searches pos and returns its evaluation from White's point of
+// view together with the engine's principal variation.
+func (a *Annotator) evaluate(pos *chess.Position) (Eval, []*chess.Move, error) {
+	switch pos.Status() {
+	case chess.Checkmate:
+		if pos.Turn() == chess.White {
+			return Eval{CP: -mateCP, Over: true}, nil, nil
+		}
+		return Eval{CP: mateCP, Over: true}, nil, nil
+	case chess.Stalemate:
+		return Eval{Over: true}, nil, nil
+	}
+
+	if err := a.engine.Run(uci.CmdPosition{Position: pos}, a.limits); err != nil {
+		return Eval{}, nil, err
+	}
+	results := a.engine.SearchResults()
+	if results.BestMove == nil {
+		return Eval{}, nil, fmt.Errorf("engine returned no best move for %s", pos)
+	}
+
+	eval := Eval{CP: results.Info.Score.CP, Mate: results.Info.Score.Mate}
+	if pos.Turn() == chess.Black {
+		eval.CP, eval.Mate = -eval.CP, -eval.Mate
+	}
+
+	// Prefer the reported PV, but fall back to the best move alone when
+	// the PV is missing or does not start with it.
+	pv := legalLine(pos, results.Info.PV)
+	if len(pv) == 0 || !sameMove(pv[0], results.BestMove) {
+		best, err := legalMove(pos, results.BestMove)
+		if err != nil {
+			return Eval{}, nil, fmt.Errorf("best move: %w", err)
+		}
+		pv = []*chess.Move{best}
+	}
+	return eval, pv, nil
+}
+
+// addVariation inserts pv, played from pos, as a variation after parent.
+// AddVariation only links the moves, so the line is first played in a
+// scratch game starting from pos, which gives each move its position.
+func (a *Annotator) addVariation(game *chess.Game, parent *chess.Move, pos *chess.Position, pv []*chess.Move) error {
+	if a.PVLength > 0 && len(pv) > a.PVLength {
+		pv = pv[:a.PVLength]
+	}
+	fen, err := chess.FEN(pos.String())
+	if err != nil {
+		return err
+	}
+	line := chess.NewGame(fen)
+	for _, m := range pv {
+		if err := line.Move(m, nil); err != nil {
+			return err
+		}
+	}
+	game.AddVariation(parent, line.GetRootMove().Children()[0])
+	return nil
+}
+
+// legalLine replays moves from pos and returns them as legal moves,
+// stopping at the first one that is not legal.
+func legalLine(pos *chess.Position, moves []*chess.Move) []*chess.Move {
+	var line []*chess.Move
+	for _, m := range moves {
+		legal, err := legalMove(pos, m)
+		if err != nil {
+			break
+		}
+		line = append(line, legal)
+		pos = pos.Update(legal)
+	}
+	return line
+}
+
+// legalMove returns the legal move of pos matching m's squares and
+// promotion. Moves decoded from engine output carry no position, so this
+// is what validates them and fills in their tags.
+func legalMove(pos *chess.Position, m *chess.Move) (*chess.Move, error) {
+	for _, legal := range pos.ValidMoves() {
+		if sameMove(&legal, m) {
+			return &legal, nil
+		}
+	}
+	return nil, fmt.Errorf("move %s is not legal in %s", m, pos)
+}
+
+func sameMove(a, b *chess.Move) bool {
+	return a.S1() == b.S1() && a.S2() == b.S2() && a.Promo() == b.Promo()
+}
+
+// addComment appends text to the move's comment.
+func addComment(m *chess.Move, text string) {
+	if c := strings.TrimSpace(m.Comments()); c != "" {
+		text = c + " " + text
+	}
+	m.SetComment(text)
+}
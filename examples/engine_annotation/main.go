@@ -0,0 +1,210 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// Usage:
+//
+//	go run . -pgn games.pgn -out annotated.pgn -depth 18
+//	go run . -pgn games.pgn -movetime 500ms -blunder 250
+//
+// Without -pgn a short demo game is annotated and printed.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file to annotate")
+	outPath := flag.String("out", "", "where to write the annotated games (default: standard output)")
+	enginePath := flag.String("engine", "stockfish", "UCI engine to run")
+	depth := flag.Int("depth", 12, "search depth per position")
+	moveTime := flag.Duration("movetime", 0, "search time per position; overrides -depth")
+	inaccuracy := flag.Int("inaccuracy", DefaultThresholds.Inaccuracy, "centipawn loss marked as an inaccuracy ($6)")
+	mistake := flag.Int("mistake", DefaultThresholds.Mistake, "centipawn loss marked as a mistake ($2)")
+	blunder := flag.Int("blunder", DefaultThresholds.Blunder, "centipawn loss marked as a blunder ($4)")
+	variations := flag.Bool("variations", true, "insert the engine's best line before each error")
+	pvLength := flag.Int("pvlen", 8, "maximum length of inserted variations (0 for no limit)")
+	flag.Parse()
+
+	limits := uci.CmdGo{Depth: *depth}
+	if *moveTime > 0 {
+		limits = uci.CmdGo{MoveTime: *moveTime}
+	}
+
+	if *pgnPath == "" {
+		runDemo(*enginePath, limits)
+		return
+	}
+
+	annotator, err := NewAnnotator(*enginePath, limits)
+	if err != nil {
+		log.Fatalf("Error starting engine: %v", err)
+	}
+	defer annotator.Close()
+	annotator.Thresholds = Thresholds{Inaccuracy: *inaccuracy, Mistake: *mistake, Blunder: *blunder}
+	annotator.Variations = *variations
+	annotator.PVLength = *pvLength
+
+	in, err := os.Open(*pgnPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer in.Close()
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := annotateAll(annotator, in, out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// annotateAll annotates every game read from r and writes it to w. Games
+// that fail to parse are reported and skipped; progress goes to stderr so
+// the PGN can be written to standard output.
+func annotateAll(annotator *Annotator, r io.Reader, w io.Writer) error {
+	scanner := chess.NewScanner(r)
+	for n := 1; scanner.HasNext(); n++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			log.Printf("Error parsing game %d: %v\n", n, err)
+			continue
+		}
+
+		start := time.Now()
+		summary, err := annotator.Annotate(game)
+		if err != nil {
+			return fmt.Errorf("game %d: %w", n, err)
+		}
+		if err := WritePGN(w, game); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Game %d (%s - %s): %s in %v\n", n,
+			game.GetTagPair("White"), game.GetTagPair("Black"),
+			summary, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("White %d/%d/%d, Black %d/%d/%d (inaccuracies/mistakes/blunders)",
+		s.Inaccuracies[chess.White], s.Mistakes[chess.White], s.Blunders[chess.White],
+		s.Inaccuracies[chess.Black], s.Mistakes[chess.Black], s.Blunders[chess.Black])
+}
+
+func runDemo(enginePath string, limits uci.CmdGo) {
+	fmt.Println("=== Engine Annotation Example ===")
+
+	// Fall back to the mock engine from ../mock_engine when the requested
+	// engine is not installed, scripted to give the annotator something
+	// to mark.
+	path := enginePath
+	if _, err := exec.LookPath(path); err != nil {
+		fmt.Printf("Note: %s not available (%v). Using the mock engine instead.\n", path, err)
+		mockPath, cleanup, err := buildMockEngine()
+		if err != nil {
+			fmt.Printf("Note: could not build the mock engine (%v). Skipping annotation.\n", err)
+			return
+		}
+		defer cleanup()
+		path = mockPath
+
+		scriptPath := filepath.Join(os.TempDir(), "engine_annotation_mock.txt")
+		if err := os.WriteFile(scriptPath, []byte(demoScript), 0o644); err != nil {
+			log.Printf("Error writing mock script: %v\n", err)
+			return
+		}
+		defer os.Remove(scriptPath)
+		os.Setenv("MOCK_ENGINE_SCRIPT", scriptPath)
+		defer os.Unsetenv("MOCK_ENGINE_SCRIPT")
+	}
+
+	annotator, err := NewAnnotator(path, limits)
+	if err != nil {
+		fmt.Printf("Note: engine not available (%v). Skipping annotation.\n", err)
+		return
+	}
+	defer annotator.Close()
+
+	// Example 1: Annotating a game
+	fmt.Println("\n1. Annotating a Game")
+	game, err := chess.NewScanner(strings.NewReader(demoPGN)).ParseNext()
+	if err != nil {
+		log.Printf("Error parsing game: %v\n", err)
+		return
+	}
+	summary, err := annotator.Annotate(game)
+	if err != nil {
+		log.Printf("Error annotating game: %v\n", err)
+		return
+	}
+	fmt.Println(summary)
+
+	// Example 2: Reading the annotations back
+	fmt.Println("\n2. Evaluations and NAGs Per Move")
+	positions := game.Positions()
+	for i, m := range game.Moves() {
+		eval, _ := m.GetCommand("eval")
+		fmt.Printf("  %-3d %-7s %-7s %-3s %s\n", i/2+1,
+			chess.AlgebraicNotation{}.Encode(positions[i], m), eval, m.NAG(), m.Comments())
+	}
+
+	// Example 3: Writing the annotated PGN
+	fmt.Println("\n3. Annotated PGN")
+	if err := WritePGN(os.Stdout, game); err != nil {
+		log.Printf("Error writing PGN: %v\n", err)
+	}
+}
+
+// buildMockEngine compiles ../mock_engine into a temporary directory and
+// returns the path of the binary and a function removing it.
+func buildMockEngine() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mock_engine")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	bin := filepath.Join(dir, "mock_engine")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Dir = filepath.Join("..", "mock_engine")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return bin, cleanup, nil
+}
+
+const demoPGN = `[Event "Annotation Demo"]
+[White "Player A"]
+[Black "Player B"]
+[Result "0-1"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 Nd4 4. Nxe5 Qg5 5. Nxf7 Qxg2 6. Rf1 Qxe4+ 7. Be2 Nf3# 0-1
+`
+
+// demoScript makes the mock engine score every position +0.30 for the side
+// to move. Each move then costs its player 60 centipawns, so every move that
+// differs from the mock's choice is marked as an inaccuracy.
+const demoScript = `name MockAnnotator
+info depth 12 score cp 30 pv {move}
+`
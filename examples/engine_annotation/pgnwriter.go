@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// knownCommands are the [%key value] commands written back out. Commands
+// cannot be listed from a move, so only these keys survive a round trip.
+var knownCommands = []string{"eval", "clk", "emt", "cal", "csl"}
+
+// WritePGN writes game as PGN including NAGs, which Game.String leaves
+// out. The tag section is taken from Game.String; the movetext is written
+// here.
+func WritePGN(w io.Writer, game *chess.Game) error {
+	var sb strings.Builder
+	if tags, _, ok := strings.Cut(game.String(), "\n\n"); ok {
+		sb.WriteString(tags)
+		sb.WriteString("\n\n")
+	}
+
+	mw := &movetextWriter{sb: &sb}
+	root := game.GetRootMove()
+	if c := strings.TrimSpace(root.Comments()); c != "" {
+		mw.token("{" + c + "}")
+		mw.forceNumber = true
+	}
+	mw.writeLine(root)
+	mw.token(game.Outcome().String())
+
+	_, err := io.WriteString(w, wrap(sb.String(), 80)+"\n\n")
+	return err
+}
+
+type movetextWriter struct {
+	sb *strings.Builder
+	// forceNumber is set after a comment or a variation, when a Black move
+	// needs its number repeated ("12...").
+	forceNumber bool
+	last        string
+}
+
+// writeLine writes the main line continuing from node, with the
+// alternatives to each move as parenthesised variations after it.
+func (mw *movetextWriter) writeLine(node *chess.Move) {
+	for len(node.Children()) > 0 {
+		children := node.Children()
+		mw.writeMove(node.Position(), children[0])
+		for _, alt := range children[1:] {
+			mw.token("(")
+			mw.forceNumber = true
+			mw.writeMove(node.Position(), alt)
+			mw.writeLine(alt)
+			mw.token(")")
+			mw.forceNumber = true
+		}
+		node = children[0]
+	}
+}
+
+// writeMove writes a single move played from pos with its number, NAG,
+// commands and comment.
+func (mw *movetextWriter) writeMove(pos *chess.Position, m *chess.Move) {
+	number := (pos.Ply() + 1) / 2
+	if pos.Turn() == chess.White {
+		mw.token(fmt.Sprintf("%d.", number))
+	} else if mw.forceNumber {
+		mw.token(fmt.Sprintf("%d...", number))
+	}
+	mw.forceNumber = false
+
+	mw.token(chess.AlgebraicNotation{}.Encode(pos, m))
+	if nag := m.NAG(); nag != "" {
+		mw.token(nag)
+	}
+
+	var comment []string
+	for _, key := range knownCommands {
+		if value, ok := m.GetCommand(key); ok {
+			comment = append(comment, "[%"+key+" "+value+"]")
+		}
+	}
+	if c := strings.TrimSpace(m.Comments()); c != "" {
+		comment = append(comment, c)
+	}
+	if len(comment) > 0 {
+		mw.token("{" + strings.Join(comment, " ") + "}")
+		mw.forceNumber = true
+	}
+}
+
+// token appends s, separated by a space except inside parentheses.
+func (mw *movetextWriter) token(s string) {
+	if mw.last != "" && mw.last != "(" && s != ")" {
+		mw.sb.WriteByte(' ')
+	}
+	mw.sb.WriteString(s)
+	mw.last = s
+}
+
+// wrap breaks the lines of s after at most width characters where
+// possible, as the PGN standard asks for.
+func wrap(s string, width int) string {
+	var out strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		if strings.HasPrefix(line, "[") {
+			out.WriteString(line)
+			continue
+		}
+		n := 0
+		for j, word := range strings.Fields(line) {
+			if j > 0 && n+1+len(word) > width {
+				out.WriteByte('\n')
+				n = 0
+			} else if j > 0 {
+				out.WriteByte(' ')
+				n++
+			}
+			out.WriteString(word)
+			n += len(word)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// Usage:
+//
+//	go run . -engine1 ./new -engine2 ./old -games 100 -tc 10+0.1 \
+//	    -openings book.epd -out match.pgn
+//
+// Add -sprt 0,5 to stop as soon as a sequential probability ratio test
+// decides whether engine 1 is stronger. Without engines a short match
+// between two copies of ../mock_engine is played.
+func main() {
+	engine1 := flag.String("engine1", "", "first UCI engine")
+	engine2 := flag.String("engine2", "", "second UCI engine")
+	name1 := flag.String("name1", "", "name of the first engine (default: its id name)")
+	name2 := flag.String("name2", "", "name of the second engine (default: its id name)")
+	games := flag.Int("games", 10, "number of games; openings are played in pairs with colours reversed")
+	tcFlag := flag.String("tc", "10+0.1", "time control in seconds, as in the PGN TimeControl tag")
+	openingsPath := flag.String("openings", "", "opening suite (.epd or .pgn)")
+	maxPlies := flag.Int("maxplies", 0, "adjudicate a draw after this many plies (0 for no limit)")
+	margin := flag.Duration("margin", 100*time.Millisecond, "how far an engine may overrun its clock")
+	outPath := flag.String("out", "match.pgn", "where to write the games")
+	sprtBounds := flag.String("sprt", "", "stop the match by SPRT with these Elo bounds, e.g. \"0,5\"")
+	alpha := flag.Float64("alpha", 0.05, "SPRT false positive rate")
+	beta := flag.Float64("beta", 0.05, "SPRT false negative rate")
+	flag.Parse()
+
+	if *engine1 == "" || *engine2 == "" {
+		runDemo()
+		return
+	}
+
+	tc, err := ParseTimeControl(*tcFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var openings []Opening
+	if *openingsPath != "" {
+		if openings, err = LoadOpenings(*openingsPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	out, err := os.Create(*outPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+
+	match := &Match{
+		Engines: [2]EngineConfig{
+			{Path: *engine1, Name: *name1},
+			{Path: *engine2, Name: *name2},
+		},
+		TimeControl: tc,
+		Openings:    openings,
+		Games:       *games,
+		MaxPlies:    *maxPlies,
+		Margin:      *margin,
+		OnGame:      printGame,
+	}
+
+	var sprt *SPRT
+	if *sprtBounds != "" {
+		var elo0, elo1 float64
+		if _, err := fmt.Sscanf(*sprtBounds, "%g,%g", &elo0, &elo1); err != nil {
+			log.Fatalf("invalid -sprt %q: %v", *sprtBounds, err)
+		}
+		sprt = NewSPRT(elo0, elo1, *alpha, *beta)
+		match.OnGame = func(r GameResult) bool {
+			printGame(r)
+			sprt.AddResult(r)
+			fmt.Println("  " + sprt.String())
+			return sprt.Status() == Continue
+		}
+	}
+
+	results, err := match.Run(out)
+	printScore(results)
+	if sprt != nil {
+		fmt.Printf("SPRT: %s\n", sprt.Status())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printGame(r GameResult) bool {
+	fmt.Printf("  Round %-3d %-12s - %-12s %-7s %-16s %3d plies  (%s)\n",
+		r.Round, r.White, r.Black, r.Outcome, r.Termination, r.Plies, r.Opening)
+	return true
+}
+
+// printScore prints the match score from the first engine's point of view.
+func printScore(results []GameResult) {
+	var wins, losses, draws int
+	var points float64
+	for _, r := range results {
+		points += r.Score()
+		switch r.Score() {
+		case 1:
+			wins++
+		case 0:
+			losses++
+		default:
+			draws++
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+	fmt.Printf("Score of engine 1: +%d -%d =%d  %.1f/%d (%.1f%%)\n",
+		wins, losses, draws, points, len(results), 100*points/float64(len(results)))
+}
+
+func runDemo() {
+	fmt.Println("=== Engine Match Example ===")
+
+	mockPath, cleanup, err := buildMockEngine()
+	if err != nil {
+		fmt.Printf("Note: could not build the mock engine (%v). Skipping the match.\n", err)
+		return
+	}
+	defer cleanup()
+
+	dir, err := os.MkdirTemp("", "engine_match")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The script is loaded after "uci", once the engine has sent its id,
+	// so the name is given here instead.
+	mock := func(name, script string) EngineConfig {
+		path := filepath.Join(dir, name+".txt")
+		if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		return EngineConfig{
+			Path:    mockPath,
+			Name:    name,
+			Options: []uci.CmdSetOption{{Name: "Script", Value: path}},
+		}
+	}
+
+	// Example 1: Time controls
+	fmt.Println("\n1. Time Controls")
+	for _, s := range []string{"5+3", "300+3", "10+0.1", "60"} {
+		tc, err := ParseTimeControl(s)
+		if err != nil {
+			log.Printf("Error parsing time control: %v\n", err)
+			continue
+		}
+		fmt.Printf("  %-7s base %-6v increment %-6v first clock %s\n", s, tc.Base, tc.Increment, formatClock(tc.Base))
+	}
+
+	// Example 2: Opening suite from EPD
+	fmt.Println("\n2. Opening Suite")
+	openings, err := ReadEPD(strings.NewReader(demoEPD))
+	if err != nil {
+		log.Printf("Error reading openings: %v\n", err)
+		return
+	}
+	for _, o := range openings {
+		fmt.Printf("  %-16s %s\n", o.Name, o.FEN)
+	}
+
+	// Example 3: A short match between two random movers
+	fmt.Println("\n3. Match Between Two Mock Engines")
+	var pgn bytes.Buffer
+	match := &Match{
+		Engines: [2]EngineConfig{
+			mock("MockAlpha", "bestmove random\nseed 1\n"),
+			mock("MockBeta", "bestmove random\nseed 2\n"),
+		},
+		TimeControl: TimeControl{Base: 5 * time.Second, Increment: 100 * time.Millisecond},
+		Openings:    openings,
+		Games:       4,
+		MaxPlies:    120,
+		Margin:      100 * time.Millisecond,
+		Event:       "Mock Match",
+		OnGame:      printGame,
+	}
+	results, err := match.Run(&pgn)
+	if err != nil {
+		log.Printf("Error running match: %v\n", err)
+	}
+	printScore(results)
+
+	// Example 4: Engine failures lose the game
+	fmt.Println("\n4. Forfeits")
+	// A crashed engine is only noticed when its clock runs out, so these
+	// games are played with a one second clock.
+	match.TimeControl = TimeControl{Base: time.Second}
+	match.Games = 1
+	for _, script := range []string{"malformed go 3", "crash go 3"} {
+		match.Engines[0] = mock("MockFaulty", "bestmove random\n"+script+"\n")
+		fmt.Printf("  Script %q:\n", script)
+		if _, err := match.Run(io.Discard); err != nil {
+			log.Printf("Error running match: %v\n", err)
+		}
+	}
+
+	// Example 5: SPRT on simulated results
+	fmt.Println("\n5. SPRT [0, 10] on Simulated Game Pairs")
+	simulateSPRT(NewSPRT(0, 10, 0.05, 0.05), 1)
+	fmt.Println("   Counting games instead of pairs:")
+	trinomial := NewSPRT(0, 10, 0.05, 0.05)
+	trinomial.Pentanomial = false
+	simulateSPRT(trinomial, 1)
+
+	// Example 6: The PGN written for the first game
+	fmt.Println("\n6. First Game PGN")
+	game, err := chess.NewScanner(&pgn).ParseNext()
+	if err != nil {
+		log.Printf("Error reading back the PGN: %v\n", err)
+		return
+	}
+	for _, tag := range []string{"White", "Black", "Result", "Termination", "TimeControl", "FEN"} {
+		fmt.Printf("  [%s %q]\n", tag, game.GetTagPair(tag))
+	}
+	moves := game.Moves()
+	if len(moves) > 0 {
+		clk, _ := moves[0].GetCommand("clk")
+		fmt.Printf("  %d plies, clock after the first move: %s\n", len(moves), clk)
+	}
+}
+
+// simulateSPRT feeds an SPRT with random game pairs in which the first
+// engine wins 40%, draws 35% and loses 25% of its games, until the test
+// reaches a decision.
+func simulateSPRT(sprt *SPRT, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+	outcome := func(engineWhite bool) chess.Outcome {
+		win, loss := chess.WhiteWon, chess.BlackWon
+		if !engineWhite {
+			win, loss = loss, win
+		}
+		switch r := rng.Float64(); {
+		case r < 0.40:
+			return win
+		case r < 0.75:
+			return chess.Draw
+		default:
+			return loss
+		}
+	}
+
+	for sprt.Status() == Continue && sprt.Games() < 100000 {
+		sprt.AddOutcome(outcome(true), true)
+		sprt.AddOutcome(outcome(false), false)
+		if sprt.Games()%200 == 0 {
+			fmt.Println("  " + sprt.String())
+		}
+	}
+	fmt.Println("  " + sprt.String())
+}
+
+// buildMockEngine compiles ../mock_engine into a temporary directory and
+// returns the path of the binary and a function removing it.
+func buildMockEngine() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mock_engine")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	bin := filepath.Join(dir, "mock_engine")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Dir = filepath.Join("..", "mock_engine")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return bin, cleanup, nil
+}
+
+const demoEPD = `rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - id "Open Game";
+rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - id "Sicilian";
+`
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// PGN Termination tag values used by the match runner.
+const (
+	TerminationNormal      = "normal"
+	TerminationTimeForfeit = "time forfeit"
+	TerminationIllegalMove = "rules infraction"
+	TerminationAbandoned   = "abandoned"
+	TerminationAdjudicated = "adjudication"
+)
+
+// EngineConfig describes how to start one of the two engines.
+type EngineConfig struct {
+	Path string
+	// Name is used in the White and Black tags. When empty the name the
+	// engine reports in its "id name" line is used.
+	Name string
+	// Options are sent to the engine after "uci", before it is asked
+	// whether it is ready. uci.New passes no arguments, so this is also
+	// how engines such as ../mock_engine are configured.
+	Options []uci.CmdSetOption
+}
+
+// GameResult is the outcome of one match game.
+type GameResult struct {
+	Round      int
+	Opening    string
+	White      string
+	Black      string
+	FirstWhite bool // the first engine had the white pieces
+	Outcome    chess.Outcome
+	// Method is how the game ended on the board. Forfeits and
+	// adjudications, which the game can only record as a resignation or an
+	// agreed draw, have NoMethod; Termination gives the reason.
+	Method      chess.Method
+	Termination string
+	Plies       int
+}
+
+// Score returns the first engine's score in the game: 1, 0.5 or 0.
+func (r GameResult) Score() float64 {
+	switch {
+	case r.Outcome == chess.Draw:
+		return 0.5
+	case (r.Outcome == chess.WhiteWon) == r.FirstWhite:
+		return 1
+	}
+	return 0
+}
+
+// Match plays games between two UCI engines. Each opening is played
+// twice with colours reversed, the first engine taking White in the first
+// game of each pair.
+type Match struct {
+	Engines     [2]EngineConfig
+	TimeControl TimeControl
+	Openings    []Opening // the standard starting position when empty
+	Games       int
+	// MaxPlies adjudicates a game as a draw after this many plies (0 for no
+	// limit).
+	MaxPlies int
+	// Margin is how far an engine may overrun its clock before it loses on
+	// time, to absorb the delay of the pipe and the process scheduler.
+	Margin time.Duration
+	Event  string
+	// OnGame is called after every game. Returning false ends the match.
+	OnGame func(GameResult) bool
+}
+
+// Run plays the match, writing every game to w as PGN, and returns the
+// results in the order the games were played.
+func (m *Match) Run(w io.Writer) ([]GameResult, error) {
+	openings := m.Openings
+	if len(openings) == 0 {
+		openings = []Opening{{Name: "Starting position"}}
+	}
+
+	var players [2]*player
+	for i, cfg := range m.Engines {
+		p := &player{config: cfg}
+		if err := p.start(); err != nil {
+			return nil, fmt.Errorf("engine %d: %w", i+1, err)
+		}
+		defer p.stop()
+		players[i] = p
+	}
+	if players[0].name == players[1].name {
+		players[1].name += " (2)"
+	}
+
+	var results []GameResult
+	for round := 1; round <= m.Games; round++ {
+		opening := openings[((round-1)/2)%len(openings)]
+		firstWhite := round%2 == 1
+		white, black := players[0], players[1]
+		if !firstWhite {
+			white, black = black, white
+		}
+
+		game, result, err := m.playGame(round, opening, white, black)
+		if err != nil {
+			return results, fmt.Errorf("round %d: %w", round, err)
+		}
+		result.FirstWhite = firstWhite
+		results = append(results, result)
+
+		if _, err := fmt.Fprintf(w, "%s\n\n", game); err != nil {
+			return results, err
+		}
+		if m.OnGame != nil && !m.OnGame(result) {
+			break
+		}
+	}
+	return results, nil
+}
+
+// playGame plays one game and returns it with its result. Engine failures
+// lose the game; only problems outside the engines are returned as errors.
+func (m *Match) playGame(round int, opening Opening, white, black *player) (*chess.Game, GameResult, error) {
+	game, err := opening.newGame()
+	if err != nil {
+		return nil, GameResult{}, err
+	}
+	event := m.Event
+	if event == "" {
+		event = "Engine Match"
+	}
+	game.AddTagPair("Event", event)
+	game.AddTagPair("Site", "Local")
+	game.AddTagPair("Date", time.Now().Format("2006.01.02"))
+	game.AddTagPair("Round", strconv.Itoa(round))
+	game.AddTagPair("White", white.name)
+	game.AddTagPair("Black", black.name)
+	game.AddTagPair("TimeControl", m.TimeControl.String())
+	game.AddTagPair("Opening", opening.Name)
+
+	for _, p := range []*player{white, black} {
+		if err := p.newGame(); err != nil {
+			return nil, GameResult{}, err
+		}
+	}
+
+	clocks := map[chess.Color]time.Duration{chess.White: m.TimeControl.Base, chess.Black: m.TimeControl.Base}
+	players := map[chess.Color]*player{chess.White: white, chess.Black: black}
+	termination := TerminationNormal
+	plies := 0
+
+	for game.Outcome() == chess.NoOutcome {
+		if m.MaxPlies > 0 && plies >= m.MaxPlies {
+			game.Draw(chess.DrawOffer)
+			termination = TerminationAdjudicated
+			break
+		}
+
+		pos := game.Position()
+		turn := pos.Turn()
+		p := players[turn]
+		cmdGo := uci.CmdGo{
+			WhiteTime:      clocks[chess.White],
+			BlackTime:      clocks[chess.Black],
+			WhiteIncrement: m.TimeControl.Increment,
+			BlackIncrement: m.TimeControl.Increment,
+		}
+
+		start := time.Now()
+		err := p.run(clocks[turn]+m.Margin, uci.CmdPosition{Position: game.GetRootMove().Position(), Moves: game.Moves()}, cmdGo)
+		elapsed := time.Since(start)
+
+		var forfeit string
+		var move *chess.Move
+		switch {
+		case errors.Is(err, errTimeout) || (err == nil && elapsed > clocks[turn]+m.Margin):
+			forfeit = TerminationTimeForfeit
+		case err != nil && writeFailed(err):
+			forfeit = TerminationAbandoned
+		case err != nil:
+			// The engine did answer, with a best move that is not a move.
+			forfeit = TerminationIllegalMove
+		default:
+			bestMove := p.engine.SearchResults().BestMove
+			if bestMove == nil {
+				// The output ended without a bestmove line.
+				forfeit = TerminationAbandoned
+				break
+			}
+			if move, err = legalMove(pos, bestMove); err != nil {
+				forfeit = TerminationIllegalMove
+			}
+		}
+		if forfeit != "" {
+			fmt.Fprintf(os.Stderr, "%s forfeits round %d: %s (%v)\n", p.name, round, forfeit, err)
+			if err != nil {
+				// The engine cannot be trusted any more; start a fresh one
+				// for the next game.
+				if err := p.restart(); err != nil {
+					return nil, GameResult{}, err
+				}
+			}
+			game.Resign(turn)
+			termination = forfeit
+			break
+		}
+
+		clocks[turn] = max(clocks[turn]-elapsed, 0) + m.TimeControl.Increment
+		if err := game.Move(move, nil); err != nil {
+			return nil, GameResult{}, err
+		}
+		plies++
+		moves := game.Moves()
+		moves[len(moves)-1].SetCommand("clk", formatClock(clocks[turn]))
+
+		// Engines do not claim draws, so claim them on their behalf.
+		for _, method := range game.EligibleDraws() {
+			if method == chess.ThreefoldRepetition || method == chess.FiftyMoveRule {
+				game.Draw(method)
+				break
+			}
+		}
+	}
+
+	game.AddTagPair("Result", game.Outcome().String())
+	game.AddTagPair("Termination", termination)
+	method := game.Method()
+	if termination != TerminationNormal {
+		method = chess.NoMethod
+	}
+	return game, GameResult{
+		Round:       round,
+		Opening:     opening.Name,
+		White:       white.name,
+		Black:       black.name,
+		Outcome:     game.Outcome(),
+		Method:      method,
+		Termination: termination,
+		Plies:       plies,
+	}, nil
+}
+
+var errTimeout = errors.New("engine did not answer in time")
+
+// writeFailed reports whether err comes from sending a command to the
+// engine, which means its process has gone away.
+func writeFailed(err error) bool {
+	var pathErr *os.PathError
+	return errors.As(err, &pathErr) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed)
+}
+
+// player is a running engine taking part in a match.
+type player struct {
+	config EngineConfig
+	name   string
+	engine *uci.Engine
+}
+
+func (p *player) start() error {
+	engine, err := uci.New(p.config.Path)
+	if err != nil {
+		return err
+	}
+	p.engine = engine
+	cmds := []uci.Cmd{uci.CmdUCI}
+	for _, o := range p.config.Options {
+		cmds = append(cmds, o)
+	}
+	cmds = append(cmds, uci.CmdIsReady)
+	if err := p.run(10*time.Second, cmds...); err != nil {
+		return err
+	}
+	p.name = p.config.Name
+	if p.name == "" {
+		p.name = engine.ID()["name"]
+	}
+	if p.name == "" {
+		p.name = p.config.Path
+	}
+	return nil
+}
+
+func (p *player) newGame() error {
+	return p.run(10*time.Second, uci.CmdUCINewGame, uci.CmdIsReady)
+}
+
+// run sends cmds to the engine and gives up after timeout. A crashed
+// engine looks the same as a hung one to the uci package, so the timeout
+// is the only way to notice either; the process is then killed.
+func (p *player) run(timeout time.Duration, cmds ...uci.Cmd) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- p.engine.Run(cmds...)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		p.kill()
+		return errTimeout
+	}
+}
+
+// kill ends the engine process. The engine must not be closed afterwards,
+// as the command that timed out still holds its lock.
+func (p *player) kill() {
+	if proc, err := os.FindProcess(p.engine.Getpid()); err == nil {
+		proc.Kill()
+	}
+	p.engine = nil
+}
+
+func (p *player) restart() error {
+	if p.engine != nil {
+		p.kill()
+	}
+	name := p.name
+	if err := p.start(); err != nil {
+		return err
+	}
+	p.name = name
+	return nil
+}
+
+func (p *player) stop() {
+	if p.engine != nil {
+		p.engine.Close()
+	}
+}
+
+// legalMove returns the legal move of pos matching m's squares and
+// promotion. Moves decoded from engine output carry no position, so this
+// is what validates them and fills in their tags.
+func legalMove(pos *chess.Position, m *chess.Move) (*chess.Move, error) {
+	for _, legal := range pos.ValidMoves() {
+		if legal.S1() == m.S1() && legal.S2() == m.S2() && legal.Promo() == m.Promo() {
+			return &legal, nil
+		}
+	}
+	return nil, fmt.Errorf("move %s is not legal in %s", m, pos)
+}
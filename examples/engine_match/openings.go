@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Opening is a starting point for match games: a position and the moves
+// played from it to reach the position the engines start from.
+type Opening struct {
+	Name  string
+	FEN   string   // empty for the standard starting position
+	Moves []string // in UCI notation
+}
+
+// newGame returns a game with the opening's moves played.
+func (o Opening) newGame() (*chess.Game, error) {
+	var game *chess.Game
+	if o.FEN == "" {
+		game = chess.NewGame()
+	} else {
+		fen, err := chess.FEN(o.FEN)
+		if err != nil {
+			return nil, err
+		}
+		game = chess.NewGame(fen)
+		game.AddTagPair("FEN", o.FEN)
+		game.AddTagPair("SetUp", "1")
+	}
+	for _, m := range o.Moves {
+		if err := game.PushNotationMove(m, chess.UCINotation{}, nil); err != nil {
+			return nil, fmt.Errorf("opening %q: %w", o.Name, err)
+		}
+	}
+	return game, nil
+}
+
+// LoadOpenings reads an opening suite. Files ending in .pgn are read as
+// PGN, using each game's main line; anything else is read as EPD, one
+// position per line.
+func LoadOpenings(path string) ([]Opening, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".pgn") {
+		return ReadPGNOpenings(f)
+	}
+	return ReadEPD(f)
+}
+
+// ReadEPD reads EPD records. The four position fields may be followed by
+// the half move clock and move number, as in FEN, and by operations; an
+// "id" operation names the opening.
+func ReadEPD(r io.Reader) ([]Opening, error) {
+	var openings []Opening
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: EPD needs at least four fields", lineNum)
+		}
+
+		fen := strings.Join(fields[:4], " ")
+		rest := strings.Join(fields[4:], " ")
+		if len(fields) >= 6 && isNumber(fields[4]) && isNumber(fields[5]) {
+			fen += " " + fields[4] + " " + fields[5]
+			rest = strings.Join(fields[6:], " ")
+		} else {
+			fen += " 0 1"
+		}
+		if _, err := chess.FEN(fen); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		name := fmt.Sprintf("EPD %d", len(openings)+1)
+		for _, op := range strings.Split(rest, ";") {
+			if id, ok := strings.CutPrefix(strings.TrimSpace(op), "id "); ok {
+				name = strings.Trim(id, `"`)
+			}
+		}
+		openings = append(openings, Opening{Name: name, FEN: fen})
+	}
+	return openings, scanner.Err()
+}
+
+// ReadPGNOpenings reads the main line of every game as an opening.
+func ReadPGNOpenings(r io.Reader) ([]Opening, error) {
+	var openings []Opening
+	scanner := chess.NewScanner(r)
+	for n := 1; scanner.HasNext(); n++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, fmt.Errorf("opening %d: %w", n, err)
+		}
+
+		o := Opening{Name: game.GetTagPair("Opening")}
+		if o.Name == "" {
+			o.Name = fmt.Sprintf("PGN %d", n)
+		}
+		if start := game.GetRootMove().Position(); start.String() != chess.StartingPosition().String() {
+			o.FEN = start.String()
+		}
+		for _, m := range game.Moves() {
+			o.Moves = append(o.Moves, chess.UCINotation{}.Encode(m.Parent().Position(), m))
+		}
+		openings = append(openings, o)
+	}
+	return openings, nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
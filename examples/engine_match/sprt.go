@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Decision is the state of a sequential probability ratio test.
+type Decision int
+
+const (
+	// Continue means more games are needed.
+	Continue Decision = iota
+	// AcceptH0 means the results favour Elo0: stop and reject the change.
+	AcceptH0
+	// AcceptH1 means the results favour Elo1: stop and accept the change.
+	AcceptH1
+)
+
+func (d Decision) String() string {
+	switch d {
+	case AcceptH0:
+		return "H0 accepted"
+	case AcceptH1:
+		return "H1 accepted"
+	}
+	return "continue"
+}
+
+// SPRT is a sequential probability ratio test of H0: elo = Elo0 against
+// H1: elo = Elo1, for the first engine of a match against the second.
+// Elo is logistic Elo and the log-likelihood ratio uses the usual normal
+// approximation of the generalised SPRT.
+//
+// Results can be counted game by game (trinomial: wins, draws, losses) or
+// by pairs of games played from the same opening with colours reversed
+// (pentanomial). Pairs cancel out most of the opening's bias and give a
+// more accurate variance, so they are preferred for paired openings.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64 // false positive and false negative rates
+
+	// Pentanomial selects pair counting. AddResult then pairs rounds 1 and
+	// 2, 3 and 4 and so on, which is how Match schedules its games. A pair
+	// with an unfinished game is left out.
+	Pentanomial bool
+
+	WDL   [3]int // first engine's wins, draws and losses
+	Pairs [5]int // pairs by the first engine's pair score: 0, ½, 1, 1½, 2
+
+	next   int             // index of the game AddOutcome records next
+	halves map[int]float64 // by pair, the score of the game seen first; NaN if unfinished
+}
+
+// NewSPRT returns a pentanomial test of [elo0, elo1] with error rates
+// alpha and beta.
+func NewSPRT(elo0, elo1, alpha, beta float64) *SPRT {
+	return &SPRT{Elo0: elo0, Elo1: elo1, Alpha: alpha, Beta: beta, Pentanomial: true}
+}
+
+// AddOutcome records the outcome of the game after the last one recorded;
+// engineWhite tells whether the first engine had the white pieces. Games
+// are paired in the order they are recorded, unfinished ones included.
+func (s *SPRT) AddOutcome(outcome chess.Outcome, engineWhite bool) {
+	s.record(s.next, outcome, engineWhite)
+}
+
+// AddResult records a match game, pairing it by its round.
+func (s *SPRT) AddResult(r GameResult) {
+	s.record(r.Round-1, r.Outcome, r.FirstWhite)
+}
+
+// record counts the game of index game, the first of a pair when even.
+func (s *SPRT) record(game int, outcome chess.Outcome, engineWhite bool) {
+	s.next = game + 1
+	score := math.NaN() // unfinished games carry no information
+	switch {
+	case outcome == chess.Draw:
+		score = 0.5
+		s.WDL[1]++
+	case outcome == chess.NoOutcome:
+	case (outcome == chess.WhiteWon) == engineWhite:
+		score = 1
+		s.WDL[0]++
+	default:
+		score = 0
+		s.WDL[2]++
+	}
+
+	pair := game / 2
+	first, ok := s.halves[pair]
+	if !ok {
+		if s.halves == nil {
+			s.halves = map[int]float64{}
+		}
+		s.halves[pair] = score
+		return
+	}
+	delete(s.halves, pair)
+	if !math.IsNaN(first) && !math.IsNaN(score) {
+		s.AddPair(first, score)
+	}
+}
+
+// AddPair records the first engine's scores in two games played from the
+// same opening. Games recorded with AddOutcome are paired automatically;
+// AddPair is for callers pairing games themselves.
+func (s *SPRT) AddPair(first, second float64) {
+	s.Pairs[int(math.Round(2*(first+second)))]++
+}
+
+// Games returns the number of games recorded.
+func (s *SPRT) Games() int {
+	return s.WDL[0] + s.WDL[1] + s.WDL[2]
+}
+
+// Bounds returns the LLR bounds: the test accepts H0 at or below lower and
+// H1 at or above upper.
+func (s *SPRT) Bounds() (lower, upper float64) {
+	return math.Log(s.Beta / (1 - s.Alpha)), math.Log((1 - s.Beta) / s.Alpha)
+}
+
+// LLR returns the log-likelihood ratio of H1 against H0.
+func (s *SPRT) LLR() float64 {
+	n, mean, variance := s.stats()
+	if n == 0 || variance == 0 {
+		return 0
+	}
+	s0, s1 := expectedScore(s.Elo0), expectedScore(s.Elo1)
+	return n * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Status returns the decision the recorded results support.
+func (s *SPRT) Status() Decision {
+	llr := s.LLR()
+	lower, upper := s.Bounds()
+	switch {
+	case llr >= upper:
+		return AcceptH1
+	case llr <= lower:
+		return AcceptH0
+	}
+	return Continue
+}
+
+// Elo returns the Elo estimate and the half width of its 95% confidence
+// interval. Scores are clamped short of 0 and 1, so while one engine has
+// scored nothing the estimate is about ±2400 rather than infinite.
+func (s *SPRT) Elo() (elo, margin float64) {
+	n, mean, variance := s.stats()
+	if n == 0 {
+		return 0, eloFromScore(1)
+	}
+	delta := 1.959964 * math.Sqrt(variance/n)
+	elo = eloFromScore(mean)
+	margin = (eloFromScore(mean+delta) - eloFromScore(mean-delta)) / 2
+	return elo, margin
+}
+
+// stats returns the number of samples and the mean and variance of the
+// per game score: per pair when counting pairs, per game otherwise.
+func (s *SPRT) stats() (n, mean, variance float64) {
+	var counts []int
+	var scores []float64
+	if s.Pentanomial {
+		counts = s.Pairs[:]
+		scores = []float64{0, 0.25, 0.5, 0.75, 1}
+	} else {
+		counts = []int{s.WDL[2], s.WDL[1], s.WDL[0]}
+		scores = []float64{0, 0.5, 1}
+	}
+
+	for i, c := range counts {
+		n += float64(c)
+		mean += float64(c) * scores[i]
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	mean /= n
+	for i, c := range counts {
+		variance += float64(c) * (scores[i] - mean) * (scores[i] - mean)
+	}
+	variance /= n
+	return n, mean, variance
+}
+
+func (s *SPRT) String() string {
+	elo, margin := s.Elo()
+	lower, upper := s.Bounds()
+	str := fmt.Sprintf("Games %d  W/D/L %d/%d/%d", s.Games(), s.WDL[0], s.WDL[1], s.WDL[2])
+	if s.Pentanomial {
+		str += fmt.Sprintf("  Ptnml %v", s.Pairs)
+	}
+	return str + fmt.Sprintf("  Elo %.1f ± %.1f  LLR %.2f (%.2f, %.2f) [%g, %g]  %s",
+		elo, margin, s.LLR(), lower, upper, s.Elo0, s.Elo1, s.Status())
+}
+
+// expectedScore returns the score expected at a logistic Elo difference.
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// eloFromScore is the inverse of expectedScore, with score clamped to
+// (minScore, 1-minScore) so that it stays finite.
+func eloFromScore(score float64) float64 {
+	score = math.Min(math.Max(score, minScore), 1-minScore)
+	return -400 * math.Log10(1/score-1)
+}
+
+const minScore = 1e-6
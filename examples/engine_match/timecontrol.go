@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeControl is a sudden death time control with an optional increment.
+type TimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+}
+
+// ParseTimeControl parses a time control in the format of the PGN
+// TimeControl tag, "base+increment" or "base", both in seconds. Fractions
+// are allowed, so "10+0.1" is ten seconds plus a tenth of a second per
+// move. Note that by this definition the "5+3" set by the comprehensive
+// example means five seconds, not five minutes; a five minute game is
+// "300+3".
+func ParseTimeControl(s string) (TimeControl, error) {
+	baseStr, incStr, hasInc := strings.Cut(strings.TrimSpace(s), "+")
+	base, err := parseSeconds(baseStr)
+	if err != nil || base <= 0 {
+		return TimeControl{}, fmt.Errorf("invalid time control %q", s)
+	}
+	tc := TimeControl{Base: base}
+	if hasInc {
+		if tc.Increment, err = parseSeconds(incStr); err != nil || tc.Increment < 0 {
+			return TimeControl{}, fmt.Errorf("invalid increment in time control %q", s)
+		}
+	}
+	return tc, nil
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// String formats the time control for the PGN TimeControl tag.
+func (tc TimeControl) String() string {
+	s := strconv.FormatFloat(tc.Base.Seconds(), 'f', -1, 64)
+	if tc.Increment > 0 {
+		s += "+" + strconv.FormatFloat(tc.Increment.Seconds(), 'f', -1, 64)
+	}
+	return s
+}
+
+// formatClock formats a remaining time for a [%clk] command, e.g.
+// "0:04:58". Tenths of a second are added for clocks under a minute.
+func formatClock(d time.Duration) string {
+	d = max(d, 0)
+	h := int(d / time.Hour)
+	m := int(d/time.Minute) % 60
+	s := int(d/time.Second) % 60
+	clock := fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	if d < time.Minute {
+		clock += fmt.Sprintf(".%d", int(d/(100*time.Millisecond))%10)
+	}
+	return clock
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Point is the evaluation after one ply, from White's point of view.
+type Point struct {
+	Ply  int
+	Eval float64 // pawns, when Mate is 0
+	Mate int     // mate in N moves; positive when White mates
+	// Move is the move in SAN with its move number, e.g. "12... Nf3";
+	// empty for points made from bare scores.
+	Move string
+	NAG  string
+}
+
+// pawns returns the evaluation with mates counted as ±limit and other
+// evaluations clamped to ±limit.
+func (p Point) pawns(limit float64) float64 {
+	switch {
+	case p.Mate > 0:
+		return limit
+	case p.Mate < 0:
+		return -limit
+	}
+	return math.Max(-limit, math.Min(limit, p.Eval))
+}
+
+func (p Point) String() string {
+	eval := fmt.Sprintf("%+.2f", p.Eval)
+	if p.Mate != 0 {
+		eval = "#" + strconv.Itoa(p.Mate)
+	}
+	if p.Move == "" {
+		return fmt.Sprintf("ply %d: %s", p.Ply, eval)
+	}
+	return p.Move + nagSymbols[p.NAG] + " " + eval
+}
+
+// GamePoints returns the [%eval] commands of the main line of game, with
+// the starting position as 0.00 at ply 0. Moves without an evaluation are
+// left out. A checkmate counts as mate for the side that gave it, whatever
+// its [%eval] command says, as in ../accuracy_report.
+func GamePoints(game *chess.Game) ([]Point, error) {
+	points := []Point{{Ply: 0}}
+	for i, m := range game.Moves() {
+		before := m.Parent().Position()
+		p := Point{
+			Ply:  i + 1,
+			Move: moveNumber(before) + chess.AlgebraicNotation{}.Encode(before, m),
+			NAG:  m.NAG(),
+		}
+		value, ok := m.GetCommand("eval")
+		switch {
+		case m.Position().Status() == chess.Checkmate:
+			p.Mate = 1
+			if m.Position().Turn() == chess.White {
+				p.Mate = -1
+			}
+		case ok:
+			if err := parseEval(value, &p); err != nil {
+				return nil, fmt.Errorf("%s: %w", p.Move, err)
+			}
+		default:
+			continue
+		}
+		points = append(points, p)
+	}
+	if len(points) == 1 {
+		return nil, fmt.Errorf("no [%%eval] commands in the game")
+	}
+	return points, nil
+}
+
+// ScorePoints turns evaluations in pawns, one per ply starting with the
+// first move, into points.
+func ScorePoints(scores []float64) []Point {
+	points := make([]Point, len(scores))
+	for i, s := range scores {
+		points[i] = Point{Ply: i + 1, Eval: s}
+	}
+	return points
+}
+
+// parseEval parses the value of an [%eval] command, "0.31", "-1.20", "#3"
+// or "#-2", into p.
+func parseEval(s string, p *Point) error {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "#"); ok {
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return fmt.Errorf("invalid mate score %q", s)
+		}
+		if n == 0 {
+			// "#0" and "#-0" mark a position that is already mate; the
+			// sign is lost, so GamePoints checks the position instead.
+			return fmt.Errorf("ambiguous mate score %q", s)
+		}
+		p.Mate = n
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid evaluation %q", s)
+	}
+	p.Eval = f
+	return nil
+}
+
+// moveNumber returns the number written before a move from pos: "12. "
+// for White, "12... " for Black.
+func moveNumber(pos *chess.Position) string {
+	n := "1"
+	if fields := strings.Fields(pos.String()); len(fields) >= 6 {
+		n = fields[5]
+	}
+	if pos.Turn() == chess.White {
+		return n + ". "
+	}
+	return n + "... "
+}
+
+var nagSymbols = map[string]string{"$1": "!", "$2": "?", "$3": "!!", "$4": "??", "$5": "!?", "$6": "?!"}
+
+// Chart sets the size and look of an evaluation graph.
+type Chart struct {
+	Width, Height int
+	// Limit is the evaluation in pawns at the top and bottom of the
+	// chart. Larger evaluations and mates are drawn at the limit.
+	Limit float64
+	Title string
+	// Markers are the colours of the dots drawn on moves with these NAGs.
+	Markers map[string]string
+}
+
+// DefaultChart is a wide chart clamped at ±5 pawns, marking mistakes and
+// blunders.
+var DefaultChart = Chart{
+	Width:   720,
+	Height:  240,
+	Limit:   5,
+	Markers: map[string]string{"$2": "#e69f00", "$4": "#d62728"},
+}
+
+const (
+	marginLeft   = 36
+	marginRight  = 12
+	marginTop    = 12
+	marginBottom = 28
+)
+
+// WriteSVG draws points as an area chart: the white area grows as White's
+// advantage does, the dark one as Black's. Points must be sorted by ply.
+func (c Chart) WriteSVG(w io.Writer, points []Point) error {
+	if len(points) == 0 {
+		return fmt.Errorf("no points to draw")
+	}
+	if c.Limit <= 0 {
+		c.Limit = DefaultChart.Limit
+	}
+	top := marginTop
+	if c.Title != "" {
+		top += 18
+	}
+	plotW := float64(c.Width - marginLeft - marginRight)
+	plotH := float64(c.Height - top - marginBottom)
+	if plotW <= 0 || plotH <= 0 {
+		return fmt.Errorf("chart of %dx%d is too small", c.Width, c.Height)
+	}
+
+	lastPly := max(points[len(points)-1].Ply, 1)
+	x := func(ply int) float64 { return marginLeft + plotW*float64(ply)/float64(lastPly) }
+	y := func(pawns float64) float64 { return float64(top) + plotH*(c.Limit-pawns)/(2*c.Limit) }
+	bottom := y(-c.Limit)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n",
+		c.Width, c.Height, c.Width, c.Height)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", c.Width, c.Height)
+	if c.Title != "" {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="13" font-weight="bold">%s</text>`+"\n", marginLeft, marginTop+10, escape(c.Title))
+	}
+	fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%s" height="%s" fill="#3a3a3a"/>`+"\n", marginLeft, top, num(plotW), num(plotH))
+
+	// White's area, from the bottom of the chart up to the line.
+	line := make([]string, len(points))
+	for i, p := range points {
+		line[i] = num(x(p.Ply)) + "," + num(y(p.pawns(c.Limit)))
+	}
+	fmt.Fprintf(&sb, `<polygon points="%s,%s %s %s,%s" fill="#f0f0f0"/>`+"\n",
+		num(x(points[0].Ply)), num(bottom), strings.Join(line, " "), num(x(points[len(points)-1].Ply)), num(bottom))
+	fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="#7d7d7d" stroke-width="1.5"/>`+"\n", strings.Join(line, " "))
+
+	// The evaluation axis, with the zero line across the chart.
+	for _, v := range []float64{c.Limit, c.Limit / 2, 0, -c.Limit / 2, -c.Limit} {
+		style := `stroke="#999999" stroke-width="0.5" stroke-dasharray="2,3"`
+		if v == 0 {
+			style = `stroke="#d0a040" stroke-width="1"`
+		}
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%s" x2="%s" y2="%s" %s/>`+"\n", marginLeft, num(y(v)), num(marginLeft+plotW), num(y(v)), style)
+		fmt.Fprintf(&sb, `<text x="%d" y="%s" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", marginLeft-4, num(y(v)), formatPawns(v))
+	}
+
+	// Move numbers below the chart, at White's moves.
+	step := moveStep(lastPly)
+	for move := 1; 2*move-1 <= lastPly; move += step {
+		px := x(2*move - 1)
+		fmt.Fprintf(&sb, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#666666"/>`+"\n", num(px), num(bottom), num(px), num(bottom+4))
+		fmt.Fprintf(&sb, `<text x="%s" y="%s" text-anchor="middle">%d</text>`+"\n", num(px), num(bottom+15), move)
+	}
+
+	for _, p := range points {
+		color, ok := c.Markers[p.NAG]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, `<circle cx="%s" cy="%s" r="4" fill="%s" stroke="#ffffff" stroke-width="1"><title>%s</title></circle>`+"\n",
+			num(x(p.Ply)), num(y(p.pawns(c.Limit))), color, escape(p.String()))
+	}
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// moveStep returns how many moves apart the x-axis labels are, so there
+// are at most about fifteen of them.
+func moveStep(plies int) int {
+	moves := (plies + 1) / 2
+	for _, step := range []int{1, 2, 5, 10, 20, 50} {
+		if moves/step <= 15 {
+			return step
+		}
+	}
+	return 100
+}
+
+func formatPawns(v float64) string {
+	if v > 0 {
+		return "+" + num(v)
+	}
+	return num(v)
+}
+
+// num formats a number with at most two decimals.
+func num(f float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", f), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+var escape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace
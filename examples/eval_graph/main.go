@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -pgn annotated.pgn -game 2 -out graph.svg -limit 8
+//	go run . -scores 0.2,0.3,-0.1,1.5 -out graph.svg
+//
+// The PGN is expected to carry [%eval] commands, as written by the
+// engine_annotation example or by Lichess exports. Without flags the demo
+// writes a few graphs into the current directory.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file with [%eval] commands")
+	gameNum := flag.Int("game", 1, "number of the game in the file, starting at 1")
+	scores := flag.String("scores", "", "comma separated evaluations in pawns, one per ply, instead of -pgn")
+	outPath := flag.String("out", "eval.svg", "output SVG file")
+	limit := flag.Float64("limit", DefaultChart.Limit, "evaluation in pawns at which the graph is clamped")
+	width := flag.Int("width", DefaultChart.Width, "width in pixels")
+	height := flag.Int("height", DefaultChart.Height, "height in pixels")
+	title := flag.String("title", "", "title drawn above the graph")
+	flag.Parse()
+
+	if *pgnPath == "" && *scores == "" {
+		runDemo()
+		return
+	}
+
+	chart := DefaultChart
+	chart.Limit, chart.Width, chart.Height, chart.Title = *limit, *width, *height, *title
+
+	var points []Point
+	if *scores != "" {
+		values, err := parseScores(*scores)
+		if err != nil {
+			log.Fatal(err)
+		}
+		points = ScorePoints(values)
+	} else {
+		game, err := readGame(*pgnPath, *gameNum)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if points, err = GamePoints(game); err != nil {
+			log.Fatal(err)
+		}
+		if chart.Title == "" {
+			chart.Title = game.GetTagPair("White") + " – " + game.GetTagPair("Black")
+		}
+	}
+	if err := writeChart(*outPath, chart, points); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Graph saved to: %s\n", *outPath)
+}
+
+// readGame returns the n-th game of the file, counting from 1.
+func readGame(path string, n int) (*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := chess.NewScanner(f)
+	for i := 1; scanner.HasNext(); i++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i, err)
+		}
+		if i == n {
+			return game, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has fewer than %d games", path, n)
+}
+
+func parseScores(s string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score %q", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func writeChart(path string, chart Chart, points []Point) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := chart.WriteSVG(f, points); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runDemo() {
+	fmt.Println("=== Evaluation Graph Example ===")
+
+	// Example 1: Reading [%eval] commands from an annotated game
+	fmt.Println("\n1. Evaluations From an Annotated Game")
+	pgn, err := chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	game := chess.NewGame(pgn)
+	points, err := GamePoints(game)
+	if err != nil {
+		log.Printf("Error reading evaluations: %v\n", err)
+		return
+	}
+	fmt.Printf("%d evaluated plies\n", len(points)-1)
+	for _, p := range points {
+		if _, marked := DefaultChart.Markers[p.NAG]; marked || p.Mate != 0 {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+
+	// Example 2: Drawing the graph
+	fmt.Println("\n2. Drawing the Graph")
+	chart := DefaultChart
+	chart.Title = "Morphy – Duke Karl / Count Isouard, Paris 1858"
+	path := filepath.Join(".", "opera_game_eval.svg")
+	if err := writeChart(path, chart, points); err != nil {
+		log.Printf("Error writing SVG: %v\n", err)
+		return
+	}
+	fmt.Printf("Graph saved to: %s\n", path)
+
+	// Example 3: A graph from bare scores with a tighter clamp
+	fmt.Println("\n3. Graph From a Slice of Scores")
+	scores := []float64{0.3, 0.25, 0.4, 0.1, 0.9, 0.8, -0.5, -0.6, -2.4, -2.1, -9.9, -12.0}
+	chart = DefaultChart
+	chart.Width, chart.Height, chart.Limit = 360, 160, 3
+	path = filepath.Join(".", "scores_eval.svg")
+	if err := writeChart(path, chart, ScorePoints(scores)); err != nil {
+		log.Printf("Error writing SVG: %v\n", err)
+		return
+	}
+	fmt.Printf("Graph of %d scores clamped at ±%g saved to: %s\n", len(scores), chart.Limit, path)
+}
+
+const demoPGN = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 { [%eval 0.3] } e5 { [%eval 0.3] } 2. Nf3 { [%eval 0.25] } d6 { [%eval 0.6] }
+3. d4 { [%eval 0.5] } Bg4 $2 { [%eval 1.6] } 4. dxe5 { [%eval 1.5] } Bxf3 { [%eval 1.6] }
+5. Qxf3 { [%eval 1.5] } dxe5 { [%eval 1.6] } 6. Bc4 { [%eval 1.5] } Nf6 { [%eval 1.7] }
+7. Qb3 { [%eval 1.6] } Qe7 { [%eval 1.9] } 8. Nc3 { [%eval 1.3] } c6 { [%eval 2.1] }
+9. Bg5 { [%eval 2.0] } b5 $4 { [%eval 5.2] } 10. Nxb5 { [%eval 5.0] } cxb5 { [%eval 5.4] }
+11. Bxb5+ { [%eval 5.3] } Nbd7 { [%eval 5.4] } 12. O-O-O { [%eval 5.2] } Rd8 { [%eval 6.8] }
+13. Rxd7 { [%eval 6.7] } Rxd7 { [%eval 7.1] } 14. Rd1 { [%eval 7.0] } Qe6 { [%eval #4] }
+15. Bxd7+ { [%eval 8.5] } Nxd7 { [%eval #2] } 16. Qb8+ { [%eval #1] } Nxb8 { [%eval #1] }
+17. Rd8# 1-0`
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/image"
+)
+
+// The viewer draws its board in the browser from two parts produced by
+// image.SVG: the empty board, once from each side, and the twelve piece
+// drawings, turned into <symbol>s the page places with <use>. Embedding a
+// full diagram for every move instead would make long games megabytes
+// large.
+
+// pieceNames maps pieces to their ids in the page, the names image.SVG
+// uses for its own piece files.
+var pieceNames = map[chess.Piece]string{
+	chess.WhiteKing: "wK", chess.WhiteQueen: "wQ", chess.WhiteRook: "wR",
+	chess.WhiteBishop: "wB", chess.WhiteKnight: "wN", chess.WhitePawn: "wP",
+	chess.BlackKing: "bK", chess.BlackQueen: "bQ", chess.BlackRook: "bR",
+	chess.BlackBishop: "bB", chess.BlackKnight: "bN", chess.BlackPawn: "bP",
+}
+
+// pieceSymbols returns the piece drawings of image.SVG as <symbol>
+// elements with the ids of pieceNames.
+func pieceSymbols() (string, error) {
+	// Draw every piece once, one per square along the first two ranks.
+	squares := map[chess.Square]chess.Piece{}
+	at := map[chess.Piece]chess.Square{}
+	sq := chess.A1
+	for _, p := range sortedPieces() {
+		squares[sq] = p
+		at[p] = sq
+		sq++
+	}
+	var buf bytes.Buffer
+	if err := image.SVG(&buf, chess.NewBoard(squares)); err != nil {
+		return "", err
+	}
+	doc := buf.String()
+
+	var sb strings.Builder
+	for _, p := range sortedPieces() {
+		sq := at[p]
+		// image.SVG places each piece with a nested <svg> whose view box
+		// is shifted by the position of its square.
+		x, y := int(sq.File())*45, (7-int(sq.Rank()))*45
+		start := strings.Index(doc, fmt.Sprintf(`viewBox="%d %d 360 360">`, -x, -y))
+		if start < 0 {
+			return "", fmt.Errorf("piece %s not found in image.SVG output", pieceNames[p])
+		}
+		start = strings.Index(doc[start:], ">") + start + 1
+		end := strings.Index(doc[start:], "</svg>") + start
+		fmt.Fprintf(&sb, `<symbol id="%s" viewBox="0 0 45 45">%s</symbol>`+"\n", pieceNames[p], strings.TrimSpace(doc[start:end]))
+	}
+	return sb.String(), nil
+}
+
+// sortedPieces lists the pieces in a fixed order, so the page is the same
+// on every run.
+func sortedPieces() []chess.Piece {
+	var pieces []chess.Piece
+	for p := chess.WhiteKing; p <= chess.BlackPawn; p++ {
+		if _, ok := pieceNames[p]; ok {
+			pieces = append(pieces, p)
+		}
+	}
+	return pieces
+}
+
+// emptyBoard returns the squares and coordinates image.SVG draws for an
+// empty board seen from c, without the enclosing <svg> element.
+func emptyBoard(c chess.Color) (string, error) {
+	var buf bytes.Buffer
+	if err := image.SVG(&buf, chess.NewBoard(map[chess.Square]chess.Piece{}), image.Perspective(c)); err != nil {
+		return "", err
+	}
+	doc := buf.String()
+	open := strings.Index(doc, "<svg")
+	if open < 0 {
+		return "", fmt.Errorf("unexpected output from image.SVG")
+	}
+	start := strings.Index(doc[open:], ">") + open + 1
+	end := strings.LastIndex(doc, "</svg>")
+	return strings.TrimSpace(doc[start:end]), nil
+}
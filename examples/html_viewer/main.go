@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -pgn games.pgn -game 3 -out game.html
+//	go run . -pgn games.pgn -out game.html -black -title "Club championship"
+//
+// The page is a single file with the board, the move list and the script
+// inline, so it can be mailed or opened offline. Without flags the demo
+// writes a viewer for an annotated game into the current directory.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file to read the game from")
+	gameNum := flag.Int("game", 1, "number of the game in the file, starting at 1")
+	outPath := flag.String("out", "game.html", "output HTML file")
+	title := flag.String("title", "", "page title (default: players and event)")
+	black := flag.Bool("black", false, "show the board from Black's side at first")
+	flag.Parse()
+
+	if *pgnPath == "" {
+		runDemo()
+		return
+	}
+
+	f, err := os.Open(*pgnPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	game, err := readGame(chess.NewScanner(f), *gameNum)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeViewer(*outPath, game, Options{Title: *title, Flipped: *black}); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Viewer saved to: %s\n", *outPath)
+}
+
+// readGame returns the n-th game of the scanner, counting from 1.
+func readGame(scanner *chess.Scanner, n int) (*chess.Game, error) {
+	for i := 1; scanner.HasNext(); i++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i, err)
+		}
+		if i == n {
+			return game, nil
+		}
+	}
+	return nil, fmt.Errorf("the file has fewer than %d games", n)
+}
+
+func writeViewer(path string, game *chess.Game, opts Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteHTML(f, game, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runDemo() {
+	fmt.Println("=== HTML Viewer Example ===")
+
+	// Example 1: A game with comments, NAGs and nested variations
+	fmt.Println("\n1. Parsing an Annotated Game")
+	game, err := readGame(chess.NewScanner(strings.NewReader(demoPGN)), 1)
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	fmt.Printf("Main line: %d moves, outcome %s\n", len(game.Moves()), game.Outcome())
+
+	// The PGN parser drops comments inside variations, so one is added to
+	// the alternative to 9... b5 to show how they are displayed.
+	if moves := game.Moves(); len(moves) > 17 && len(moves[17].Parent().Children()) > 1 {
+		alt := moves[17].Parent().Children()[1]
+		alt.SetComment("Trading queens is better.")
+		fmt.Println("Comment added to the variation 9... Qb4")
+	}
+
+	// Example 2: Exporting the viewer
+	fmt.Println("\n2. Exporting the HTML Viewer")
+	path := filepath.Join(".", "morphy_viewer.html")
+	if err := writeViewer(path, game, Options{}); err != nil {
+		log.Printf("Error writing HTML: %v\n", err)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("Error reading back the file: %v\n", err)
+		return
+	}
+	fmt.Printf("Viewer saved to: %s (%d KB)\n", path, info.Size()/1024)
+	fmt.Println("Open it in a browser and use the arrow keys to step through the game")
+
+	// Example 3: The same game from Black's side with a custom title
+	fmt.Println("\n3. Flipped Board and Custom Title")
+	path = filepath.Join(".", "morphy_viewer_black.html")
+	if err := writeViewer(path, game, Options{Title: "The Opera Game, seen by the Duke", Flipped: true}); err != nil {
+		log.Printf("Error writing HTML: %v\n", err)
+		return
+	}
+	fmt.Printf("Viewer saved to: %s\n", path)
+}
+
+const demoPGN = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+{ The Opera Game, played during a performance of The Barber of Seville. }
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 $2 { Pinning the knight, but giving up the bishop pair. }
+(3... exd4 4. Nxd4 Nf6) 4. dxe5 Bxf3 (4... dxe5 5. Qxd8+ Kxd8 6. Nxe5)
+5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 b5 $2 (9... Qb4
+10. Qxb4 Bxb4) 10. Nxb5 $1 cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8
+13. Rxd7 $1 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7 (15... Qxd7 16. Qb8+ Ke7 17. Qxe5+
+(17. Bxf6+ gxf6 18. Qb4+) 17... Kd8 18. Bxf6+ gxf6 19. Qxf6+) 16. Qb8+ $3 Nxb8
+17. Rd8# { Checkmate with the last two pieces. } 1-0`
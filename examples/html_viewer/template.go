@@ -0,0 +1,179 @@
+package main
+
+import "html/template"
+
+// pageTemplate is the whole viewer: styles, board and script are inline
+// so the file works when opened from disk without a network.
+var pageTemplate = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+.viewer { display: flex; gap: 2em; align-items: flex-start; flex-wrap: wrap; }
+#board { width: 480px; height: 480px; }
+.controls { margin-top: .5em; text-align: center; }
+.controls button { font-size: 1em; min-width: 3em; }
+.side { max-width: 40em; flex: 1; }
+#comment { min-height: 3em; padding: .5em; background: #f4f1ea; border-left: 3px solid #a57551; margin-bottom: 1em; white-space: pre-wrap; }
+#moves { line-height: 1.8; max-height: 420px; overflow-y: auto; }
+.move { cursor: pointer; padding: 1px 3px; border-radius: 3px; font-weight: bold; }
+.variation .move { font-weight: normal; }
+.move:hover { background: #e0d4bf; }
+.move.current { background: #a57551; color: #fff; }
+.variation { color: #555; }
+.variation .variation { color: #777; }
+.comment { color: #2a6a2a; font-style: italic; }
+.tags { color: #666; font-size: .9em; margin-bottom: 1em; }
+.help { color: #888; font-size: .8em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="tags">{{range .Tags}}<span><b>{{index . 0}}</b> {{index . 1}}</span> &nbsp; {{end}}</div>
+<div class="viewer">
+<div>
+<svg id="board" viewBox="0 0 360 360" xmlns="http://www.w3.org/2000/svg">
+<defs>
+{{.Symbols}}
+</defs>
+<g id="white-board">{{.WhiteBoard}}</g>
+<g id="black-board">{{.BlackBoard}}</g>
+<g id="marks"></g>
+<g id="pieces"></g>
+</svg>
+<div class="controls">
+<button id="first" title="Start (Home)">&#x23EE;</button>
+<button id="prev" title="Back (&#x2190;)">&#x25C0;</button>
+<button id="next" title="Forward (&#x2192;)">&#x25B6;</button>
+<button id="last" title="End of line (End)">&#x23ED;</button>
+<button id="flip" title="Flip board (f)">&#x21C5;</button>
+</div>
+</div>
+<div class="side">
+<div id="comment"></div>
+<div id="moves">{{.MoveList}}</div>
+<p class="help">&#x2190; &#x2192; move back and forward, &#x2191; &#x2193; switch between variations, Home and End jump to the start and the end of the line, f flips the board. Click a move to go to it.</p>
+</div>
+</div>
+<script>
+"use strict";
+const nodes = {{.Nodes}};
+const svgNS = "http://www.w3.org/2000/svg";
+let current = 0;
+let flipped = {{.Flipped}};
+
+function squareXY(sq) {
+	const file = sq.charCodeAt(0) - 97, rank = sq.charCodeAt(1) - 49;
+	const col = flipped ? 7 - file : file, row = flipped ? rank : 7 - rank;
+	return [col * 45, row * 45];
+}
+
+function svgElement(name, attrs) {
+	const el = document.createElementNS(svgNS, name);
+	for (const [k, v] of Object.entries(attrs)) {
+		el.setAttribute(k, v);
+	}
+	return el;
+}
+
+function render() {
+	const node = nodes[current];
+	document.getElementById("white-board").style.display = flipped ? "none" : "";
+	document.getElementById("black-board").style.display = flipped ? "" : "none";
+
+	const marks = document.getElementById("marks");
+	marks.replaceChildren();
+	if (node.from) {
+		for (const sq of [node.from, node.to]) {
+			const [x, y] = squareXY(sq);
+			marks.appendChild(svgElement("rect", {x: x, y: y, width: 45, height: 45, style: "fill:#ffff00;fill-opacity:0.35"}));
+		}
+	}
+
+	const pieces = document.getElementById("pieces");
+	pieces.replaceChildren();
+	node.board.split("/").forEach((row, i) => {
+		let file = 0;
+		for (const ch of row) {
+			if (ch >= "1" && ch <= "8") {
+				file += Number(ch);
+				continue;
+			}
+			const name = (ch === ch.toUpperCase() ? "w" : "b") + ch.toUpperCase();
+			const [x, y] = squareXY(String.fromCharCode(97 + file) + (8 - i));
+			pieces.appendChild(svgElement("use", {href: "#" + name, x: x, y: y, width: 45, height: 45}));
+			file++;
+		}
+	});
+
+	for (const el of document.querySelectorAll(".move.current")) {
+		el.classList.remove("current");
+	}
+	const move = document.querySelector('.move[data-id="' + current + '"]');
+	if (move) {
+		move.classList.add("current");
+		move.scrollIntoView({block: "nearest"});
+	}
+	const comment = document.getElementById("comment");
+	comment.textContent = node.comment || "";
+	comment.style.visibility = node.comment ? "visible" : "hidden";
+}
+
+function go(id) {
+	if (id !== undefined && id >= 0 && id < nodes.length) {
+		current = id;
+		render();
+	}
+}
+
+function sibling(step) {
+	const node = nodes[current];
+	if (node.parent < 0) {
+		return;
+	}
+	const siblings = nodes[node.parent].children;
+	go(siblings[siblings.indexOf(current) + step]);
+}
+
+function lineEnd() {
+	let id = current;
+	while (nodes[id].children.length > 0) {
+		id = nodes[id].children[0];
+	}
+	go(id);
+}
+
+document.getElementById("first").onclick = () => go(0);
+document.getElementById("prev").onclick = () => go(nodes[current].parent);
+document.getElementById("next").onclick = () => go(nodes[current].children[0]);
+document.getElementById("last").onclick = lineEnd;
+document.getElementById("flip").onclick = () => { flipped = !flipped; render(); };
+document.getElementById("moves").addEventListener("click", e => {
+	const move = e.target.closest(".move");
+	if (move) {
+		go(Number(move.dataset.id));
+	}
+});
+document.addEventListener("keydown", e => {
+	const actions = {
+		ArrowLeft: () => go(nodes[current].parent),
+		ArrowRight: () => go(nodes[current].children[0]),
+		ArrowUp: () => sibling(-1),
+		ArrowDown: () => sibling(1),
+		Home: () => go(0),
+		End: lineEnd,
+		f: () => { flipped = !flipped; render(); },
+	};
+	if (actions[e.key] && !e.ctrlKey && !e.metaKey && !e.altKey) {
+		e.preventDefault();
+		actions[e.key]();
+	}
+});
+render();
+</script>
+</body>
+</html>
+`))
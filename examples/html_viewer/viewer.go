@@ -0,0 +1,200 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// node is one position of the game tree as the page sees it. Nodes are
+// numbered in depth first order with the starting position as 0.
+type node struct {
+	Parent   int    `json:"parent"`
+	Children []int  `json:"children"`
+	Board    string `json:"board"` // piece placement field of the FEN
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Label    string `json:"label,omitempty"` // e.g. "12... Nf3!"
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Options customises the exported page.
+type Options struct {
+	// Title of the page; the players and event are used when empty.
+	Title string
+	// Flipped shows the board from Black's side at first.
+	Flipped bool
+}
+
+// WriteHTML writes game as a single HTML page with an interactive board,
+// the move list with its variations and comments, and keyboard
+// navigation. The page needs no network access.
+func WriteHTML(w io.Writer, game *chess.Game, opts Options) error {
+	symbols, err := pieceSymbols()
+	if err != nil {
+		return err
+	}
+	whiteBoard, err := emptyBoard(chess.White)
+	if err != nil {
+		return err
+	}
+	blackBoard, err := emptyBoard(chess.Black)
+	if err != nil {
+		return err
+	}
+
+	root := game.GetRootMove()
+	ml := &moveList{ids: map[*chess.Move]int{}}
+	ml.addNode(root, -1)
+	if c := strings.TrimSpace(root.Comments()); c != "" {
+		ml.sb.WriteString(`<span class="comment">` + html.EscapeString(c) + "</span> ")
+		ml.forceNumber = true
+	}
+	ml.writeLine(root)
+	ml.sb.WriteString(`<span class="result">` + html.EscapeString(game.Outcome().String()) + "</span>")
+
+	title := opts.Title
+	if title == "" {
+		title = gameTitle(game)
+	}
+	var tags [][2]string
+	for _, k := range []string{"Event", "Site", "Date", "Round", "White", "Black", "Result", "ECO"} {
+		if v := game.GetTagPair(k); v != "" && v != "?" && v != "????.??.??" {
+			tags = append(tags, [2]string{k, v})
+		}
+	}
+
+	// The SVG fragments come from image.SVG and the move list is escaped
+	// as it is built, so both are inserted as they are.
+	return pageTemplate.Execute(w, struct {
+		Title      string
+		Tags       [][2]string
+		Symbols    template.HTML
+		WhiteBoard template.HTML
+		BlackBoard template.HTML
+		MoveList   template.HTML
+		Nodes      []node
+		Flipped    bool
+	}{
+		Title:      title,
+		Tags:       tags,
+		Symbols:    template.HTML(symbols),
+		WhiteBoard: template.HTML(whiteBoard),
+		BlackBoard: template.HTML(blackBoard),
+		MoveList:   template.HTML(ml.sb.String()),
+		Nodes:      ml.nodes,
+		Flipped:    opts.Flipped,
+	})
+}
+
+func gameTitle(game *chess.Game) string {
+	white, black := game.GetTagPair("White"), game.GetTagPair("Black")
+	if white == "" && black == "" {
+		return "Game viewer"
+	}
+	title := white + " – " + black
+	if event := game.GetTagPair("Event"); event != "" && event != "?" {
+		title += ", " + event
+	}
+	return title
+}
+
+// moveList builds the nodes of the page and the HTML of the move list in
+// one walk over the game tree.
+type moveList struct {
+	sb    strings.Builder
+	nodes []node
+	ids   map[*chess.Move]int
+	// forceNumber is set after a comment or a variation, when a Black move
+	// needs its number repeated ("12...").
+	forceNumber bool
+}
+
+// writeLine writes the main line continuing from m, with the alternatives
+// to each move as nested variations after it.
+func (ml *moveList) writeLine(m *chess.Move) {
+	for len(m.Children()) > 0 {
+		children := m.Children()
+		ml.writeMove(children[0])
+		for _, alt := range children[1:] {
+			ml.sb.WriteString(`<span class="variation">(`)
+			ml.forceNumber = true
+			ml.writeMove(alt)
+			ml.writeLine(alt)
+			ml.sb.WriteString(")</span> ")
+			ml.forceNumber = true
+		}
+		m = children[0]
+	}
+}
+
+func (ml *moveList) writeMove(m *chess.Move) {
+	before := m.Parent().Position()
+	label := chess.AlgebraicNotation{}.Encode(before, m) + nagSymbol(m.NAG())
+	switch {
+	case before.Turn() == chess.White:
+		label = moveNumber(before) + ". " + label
+	case ml.forceNumber:
+		label = moveNumber(before) + "... " + label
+	}
+	ml.forceNumber = false
+
+	id := ml.addNode(m, ml.ids[m.Parent()])
+	ml.nodes[id].Label = label
+	ml.sb.WriteString(`<span class="move" data-id="` + strconv.Itoa(id) + `">` + html.EscapeString(label) + "</span> ")
+	if c := strings.TrimSpace(m.Comments()); c != "" {
+		ml.sb.WriteString(`<span class="comment">` + html.EscapeString(c) + "</span> ")
+		ml.forceNumber = true
+	}
+}
+
+// addNode adds m to the nodes as a child of the node parent.
+func (ml *moveList) addNode(m *chess.Move, parent int) int {
+	id := len(ml.nodes)
+	n := node{
+		Parent:   parent,
+		Children: []int{},
+		Board:    strings.Fields(m.Position().String())[0],
+		Comment:  strings.TrimSpace(m.Comments()),
+	}
+	if parent >= 0 {
+		n.From, n.To = m.S1().String(), m.S2().String()
+		ml.nodes[parent].Children = append(ml.nodes[parent].Children, id)
+	}
+	ml.nodes = append(ml.nodes, n)
+	ml.ids[m] = id
+	return id
+}
+
+// moveNumber returns the full move number of pos from its FEN.
+func moveNumber(pos *chess.Position) string {
+	fields := strings.Fields(pos.String())
+	if len(fields) < 6 {
+		return "1"
+	}
+	return fields[5]
+}
+
+// nagSymbols are the usual symbols of the common NAGs.
+var nagSymbols = map[string]string{
+	"$1": "!", "$2": "?", "$3": "!!", "$4": "??", "$5": "!?", "$6": "?!",
+	"$10": " =", "$13": " ∞", "$14": " ⩲", "$15": " ⩱", "$16": " ±", "$17": " ∓",
+	"$18": " +−", "$19": " −+",
+}
+
+func nagSymbol(nag string) string {
+	if nag == "" {
+		return ""
+	}
+	if s, ok := nagSymbols[nag]; ok {
+		return s
+	}
+	if strings.HasPrefix(nag, "$") {
+		return " " + nag
+	}
+	return nag
+}
@@ -0,0 +1,345 @@
+// Command mock_engine is a small, scriptable UCI engine for testing code
+// built on the uci package without a real engine installed. It speaks
+// uci, isready, ucinewgame, setoption, position, go, stop and quit, and can
+// be told to crash, hang or send malformed output (see Script).
+//
+// uci.New starts engines without arguments, so the script is passed
+// through the environment or, to keep the environment of the process
+// starting the engine alone, as the Script UCI option:
+//
+//	go build -o /tmp/mock_engine .
+//	MOCK_ENGINE_SCRIPT=crash.txt go run ../uci_analysis -engine /tmp/mock_engine
+//	setoption name Script value crash.txt   # from the GUI, after "uci"
+//
+// Environment variables:
+//
+//	MOCK_ENGINE_SCRIPT  path of a script file
+//	MOCK_ENGINE_LOG     path of a file every received command is appended to
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+func main() {
+	scriptPath := flag.String("script", os.Getenv("MOCK_ENGINE_SCRIPT"), "script file describing the engine's behaviour")
+	logPath := flag.String("log", os.Getenv("MOCK_ENGINE_LOG"), "file to append received commands to")
+	flag.Parse()
+
+	script := defaultScript()
+	if *scriptPath != "" {
+		var err error
+		if script, err = loadScript(*scriptPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var commandLog io.Writer
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		commandLog = f
+	}
+
+	e := newEngine(script, os.Stdout, commandLog)
+	e.run(os.Stdin)
+}
+
+// engine holds the state of the mock engine between commands.
+type engine struct {
+	script *Script
+	out    *bufio.Writer
+	outMu  sync.Mutex
+	log    io.Writer
+	rng    *rand.Rand
+
+	pos     *chess.Position
+	multiPV int
+	counts  map[string]int
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newEngine(script *Script, out io.Writer, commandLog io.Writer) *engine {
+	return &engine{
+		script:  script,
+		out:     bufio.NewWriter(out),
+		log:     commandLog,
+		rng:     rand.New(rand.NewSource(script.Seed)),
+		pos:     chess.StartingPosition(),
+		multiPV: 1,
+		counts:  map[string]int{},
+	}
+}
+
+func (e *engine) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if e.log != nil {
+			fmt.Fprintln(e.log, line)
+		}
+
+		command, args, _ := strings.Cut(line, " ")
+		e.counts[command]++
+		if fault, ok := e.script.faultFor(command, e.counts[command]); ok {
+			e.misbehave(fault)
+			continue
+		}
+
+		switch command {
+		case "uci":
+			e.send("id name " + e.script.Name)
+			e.send("id author " + e.script.Author)
+			for _, o := range e.script.Options {
+				e.send(o)
+			}
+			e.send("uciok")
+		case "isready":
+			e.send("readyok")
+		case "setoption":
+			e.setOption(args)
+		case "ucinewgame", "debug", "ponderhit":
+			// Nothing to do.
+		case "position":
+			if err := e.setPosition(args); err != nil {
+				e.send("info string " + err.Error())
+			}
+		case "go":
+			e.goSearch(args)
+		case "stop":
+			e.stopSearch()
+		case "quit":
+			e.stopSearch()
+			return
+		default:
+			e.send("info string unknown command " + command)
+		}
+	}
+	e.stopSearch()
+}
+
+// send writes a line to the GUI and flushes it straight away, as the GUI
+// waits for whole lines.
+func (e *engine) send(line string) {
+	e.outMu.Lock()
+	defer e.outMu.Unlock()
+	e.out.WriteString(line + "\n")
+	e.out.Flush()
+}
+
+func (e *engine) misbehave(fault Fault) {
+	switch fault.Kind {
+	case "crash":
+		e.send("info string crashing on " + fault.Command)
+		os.Exit(1)
+	case "hang":
+		// Keep the process alive but never answer again.
+		for {
+			time.Sleep(time.Hour)
+		}
+	case "malformed":
+		e.send("info depth x seldepth ? score cp nonsense pv e9e9")
+		e.send("bestmove zz99")
+	}
+}
+
+// setOption handles "setoption name <name> [value <value>]". MultiPV sets
+// the number of lines searched and Script loads a script in place of the
+// current one; other options are accepted and ignored. A running search is
+// stopped first, as it reads both.
+func (e *engine) setOption(args string) {
+	e.stopSearch()
+	name, value, _ := strings.Cut(strings.TrimPrefix(args, "name "), " value ")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	switch {
+	case strings.EqualFold(name, "MultiPV"):
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			e.multiPV = n
+		}
+	case strings.EqualFold(name, "Script"):
+		script, err := loadScript(value)
+		if err != nil {
+			e.send("info string " + err.Error())
+			return
+		}
+		e.script = script
+		e.rng = rand.New(rand.NewSource(script.Seed))
+	}
+}
+
+// setPosition handles "position [startpos | fen <fen>] [moves <m1> ...]".
+// A running search is stopped first, as it reads the position.
+func (e *engine) setPosition(args string) error {
+	e.stopSearch()
+	var fen, moves string
+	switch {
+	case strings.HasPrefix(args, "startpos"):
+		fen = chess.StartingPosition().String()
+		_, moves, _ = strings.Cut(args, "moves")
+	case strings.HasPrefix(args, "fen "):
+		fen, moves, _ = strings.Cut(strings.TrimPrefix(args, "fen "), "moves")
+	default:
+		return fmt.Errorf("invalid position command %q", args)
+	}
+
+	fenGame, err := chess.FEN(strings.TrimSpace(fen))
+	if err != nil {
+		return err
+	}
+	pos := chess.NewGame(fenGame).Position()
+	for _, s := range strings.Fields(moves) {
+		m, err := chess.UCINotation{}.Decode(pos, s)
+		if err != nil {
+			return err
+		}
+		pos = pos.Update(m)
+	}
+	e.pos = pos
+	return nil
+}
+
+// goSearch answers "go". Timed and depth limited searches reply straight
+// away; infinite and ponder searches keep sending info lines until stop.
+func (e *engine) goSearch(args string) {
+	e.stopSearch()
+
+	best := e.chooseMove()
+	fields := strings.Fields(args)
+	infinite := contains(fields, "infinite") || contains(fields, "ponder")
+
+	if !infinite {
+		e.sendInfos(best, nil)
+		e.sendBestMove(best)
+		return
+	}
+
+	e.stop = make(chan struct{})
+	e.stopped = make(chan struct{})
+	go func(stop, stopped chan struct{}) {
+		defer close(stopped)
+		for e.sendInfos(best, stop) && !wait(stop, 100*time.Millisecond) {
+		}
+		e.sendBestMove(best)
+	}(e.stop, e.stopped)
+}
+
+func (e *engine) stopSearch() {
+	if e.stop == nil {
+		return
+	}
+	close(e.stop)
+	<-e.stopped
+	e.stop, e.stopped = nil, nil
+}
+
+// sendInfos sends the scripted info lines, or a made up search when there
+// are none. It returns false if stop was closed before it finished.
+func (e *engine) sendInfos(best string, stop chan struct{}) bool {
+	infos := e.script.Infos
+	if len(infos) == 0 && best != "" {
+		infos = e.madeUpSearch(best)
+	}
+	for _, info := range infos {
+		if wait(stop, e.script.Delay) {
+			return false
+		}
+		e.send(strings.ReplaceAll(info, "{move}", best))
+	}
+	return true
+}
+
+// madeUpSearch returns three iterations of a search with one line per
+// MultiPV slot: the move about to be played first, then other legal moves
+// with slightly worse scores.
+func (e *engine) madeUpSearch(best string) []string {
+	pvs := []string{best}
+	for _, m := range e.pos.ValidMoves() {
+		if len(pvs) == e.multiPV {
+			break
+		}
+		uciMove := chess.UCINotation{}.Encode(e.pos, &m)
+		if uciMove != best {
+			pvs = append(pvs, uciMove)
+		}
+	}
+
+	var infos []string
+	for depth := 1; depth <= 3; depth++ {
+		nodes := depth * depth * 100
+		for i, pv := range pvs {
+			infos = append(infos, fmt.Sprintf(
+				"info depth %d seldepth %d multipv %d score cp %d nodes %d nps %d time %d pv %s",
+				depth, depth+1, i+1, 10+4*depth-15*i, nodes, nodes*1000/depth, depth, pv))
+		}
+	}
+	return infos
+}
+
+// wait sleeps for d and reports whether stop was closed in the meantime.
+// A nil stop channel never fires.
+func wait(stop chan struct{}, d time.Duration) bool {
+	select {
+	case <-stop:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (e *engine) sendBestMove(best string) {
+	switch {
+	case best == "":
+		e.send("bestmove (none)")
+	case e.script.Ponder != "":
+		e.send("bestmove " + best + " ponder " + e.script.Ponder)
+	default:
+		e.send("bestmove " + best)
+	}
+}
+
+// chooseMove returns the move to play in UCI notation: the scripted move,
+// or with "auto" the first legal move and with "random" a random one.
+func (e *engine) chooseMove() string {
+	if e.script.BestMove != "auto" && e.script.BestMove != "random" {
+		return e.script.BestMove
+	}
+
+	moves := e.pos.ValidMoves()
+	if len(moves) == 0 {
+		return ""
+	}
+	m := moves[0]
+	if e.script.BestMove == "random" {
+		m = moves[e.rng.Intn(len(moves))]
+	}
+	return chess.UCINotation{}.Encode(e.pos, &m)
+}
+
+func contains(fields []string, s string) bool {
+	for _, f := range fields {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
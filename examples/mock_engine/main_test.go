@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// The end to end tests start the test binary itself as the engine, the way
+// uci.New starts a real one; with runAsEngine set it runs main instead of
+// the tests.
+const runAsEngine = "MOCK_ENGINE_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runAsEngine) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript(strings.NewReader(`
+# comments and blank lines are skipped
+name TestFish
+option name Threads type spin default 1 min 1 max 8
+delay 5ms
+info depth 1 score cp 20 pv {move}
+bestmove e2e4 ponder e7e5
+crash go 3
+hang isready
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script.Name != "TestFish" || script.Author != "chess examples" {
+		t.Errorf("name, author = %q, %q", script.Name, script.Author)
+	}
+	if len(script.Options) != 1 {
+		t.Errorf("options = %q, want the scripted one only", script.Options)
+	}
+	if script.BestMove != "e2e4" || script.Ponder != "e7e5" || script.Delay != 5*time.Millisecond {
+		t.Errorf("bestmove %q ponder %q delay %v", script.BestMove, script.Ponder, script.Delay)
+	}
+	if f, ok := script.faultFor("go", 3); !ok || f.Kind != "crash" {
+		t.Errorf("faultFor(go, 3) = %v, %t", f, ok)
+	}
+	if _, ok := script.faultFor("go", 1); ok {
+		t.Error("faultFor(go, 1) found a fault")
+	}
+
+	script, err = ParseScript(strings.NewReader(`
+name MockFish 1.0  # "id name" reply
+delay 20ms	# pause before each info line
+bestmove auto  # play the first legal move
+info string "#1 in the list" # quoted # is kept
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script.Name != "MockFish 1.0" || script.Delay != 20*time.Millisecond || script.BestMove != "auto" {
+		t.Errorf("inline comments: name %q delay %v bestmove %q", script.Name, script.Delay, script.BestMove)
+	}
+	if want := `info string "#1 in the list"`; len(script.Infos) != 1 || script.Infos[0] != want {
+		t.Errorf("infos %q, want %q", script.Infos, want)
+	}
+
+	for _, bad := range []string{"explode go", "crash", "crash go zero", "delay soon"} {
+		if _, err := ParseScript(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseScript(%q) succeeded", bad)
+		}
+	}
+}
+
+// firstMove returns the first legal move after moves from the starting
+// position, the one "bestmove auto" plays.
+func firstMove(t *testing.T, moves ...string) string {
+	t.Helper()
+	pos := chess.StartingPosition()
+	for _, s := range moves {
+		m, err := chess.UCINotation{}.Decode(pos, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos = pos.Update(m)
+	}
+	m := pos.ValidMoves()[0]
+	return chess.UCINotation{}.Encode(pos, &m)
+}
+
+// session runs an engine on commands and returns the lines it sent.
+func session(t *testing.T, script *Script, commands ...string) []string {
+	t.Helper()
+	var out strings.Builder
+	e := newEngine(script, &out, nil)
+	e.run(strings.NewReader(strings.Join(commands, "\n")))
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestSearch(t *testing.T) {
+	lines := session(t, defaultScript(),
+		"uci",
+		"setoption name MultiPV value 2",
+		"position startpos moves e2e4",
+		"go depth 3",
+	)
+	if want := "bestmove " + firstMove(t, "e2e4"); lines[len(lines)-1] != want {
+		t.Errorf("last line %q, want %q", lines[len(lines)-1], want)
+	}
+	var infos int
+	for _, line := range lines {
+		if strings.HasPrefix(line, "info depth 3 ") && strings.Contains(line, " multipv 2 ") {
+			infos++
+		}
+	}
+	if infos != 1 {
+		t.Errorf("%d second lines at depth 3, want 1:\n%s", infos, strings.Join(lines, "\n"))
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	script := defaultScript()
+	script.Faults = []Fault{{Kind: "malformed", Command: "go", Count: 2}}
+	lines := session(t, script, "go movetime 10", "go movetime 10")
+	if got := lines[len(lines)-1]; got != "bestmove zz99" {
+		t.Errorf("answer to the second go is %q", got)
+	}
+}
+
+func TestScriptOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.txt")
+	if err := os.WriteFile(path, []byte("bestmove e2e4\ninfo depth 1 pv {move}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines := session(t, defaultScript(),
+		"setoption name Script value "+path,
+		"go movetime 10",
+		"setoption name Script value "+path+".missing",
+	)
+	want := []string{"info depth 1 pv e2e4", "bestmove e2e4"}
+	if len(lines) != 3 || lines[0] != want[0] || lines[1] != want[1] {
+		t.Fatalf("lines %q, want %q and an error", lines, want)
+	}
+	if !strings.HasPrefix(lines[2], "info string ") {
+		t.Errorf("missing script answered %q", lines[2])
+	}
+}
+
+// TestInfiniteSearch changes the position and options while a search is
+// running; run with -race to check that the search does not read them as
+// they change.
+func TestInfiniteSearch(t *testing.T) {
+	script := defaultScript()
+	script.Delay = time.Millisecond
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	e := newEngine(script, outW, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.run(inR)
+		outW.Close()
+	}()
+
+	var bestMoves []string
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		lines := bufio.NewScanner(outR)
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), "bestmove") {
+				bestMoves = append(bestMoves, lines.Text())
+			}
+		}
+	}()
+	for _, cmd := range []string{
+		"go infinite",
+		"setoption name MultiPV value 3",
+		"go infinite",
+		"position startpos moves d2d4",
+		"go infinite",
+		"stop",
+		"quit",
+	} {
+		io.WriteString(inW, cmd+"\n")
+		time.Sleep(20 * time.Millisecond)
+	}
+	inW.Close()
+	<-done
+	<-read
+
+	if len(bestMoves) != 3 {
+		t.Fatalf("bestmoves %q, want one per go", bestMoves)
+	}
+	if want := "bestmove " + firstMove(t, "d2d4"); bestMoves[2] != want {
+		t.Errorf("search after position answered %q, want %q", bestMoves[2], want)
+	}
+}
+
+// startEngine starts the mock engine through uci.New with script.
+func startEngine(t *testing.T, script string) *uci.Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.txt")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(runAsEngine, "1")
+	t.Setenv("MOCK_ENGINE_SCRIPT", path)
+	engine, err := uci.New(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+var errTimeout = errors.New("engine did not answer in time")
+
+// run runs cmds on the engine, giving up after a second. The uci package
+// waits for output forever, also from an engine that has exited, so a
+// command that got no answer is left blocked and the engine killed.
+func run(engine *uci.Engine, cmds ...uci.Cmd) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.Run(cmds...)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		return errTimeout
+	}
+}
+
+// alive reports whether the engine process is still running.
+func alive(engine *uci.Engine) bool {
+	p, err := os.FindProcess(engine.Getpid())
+	return err == nil && p.Signal(syscall.Signal(0)) == nil
+}
+
+func kill(engine *uci.Engine) {
+	if p, err := os.FindProcess(engine.Getpid()); err == nil {
+		p.Kill()
+	}
+}
+
+func TestEngineSearch(t *testing.T) {
+	engine := startEngine(t, "name TestFish\nbestmove auto\n")
+	defer engine.Close()
+
+	pos := chess.StartingPosition()
+	err := run(engine,
+		uci.CmdUCI,
+		uci.CmdIsReady,
+		uci.CmdSetOption{Name: "MultiPV", Value: "2"},
+		uci.CmdUCINewGame,
+		uci.CmdPosition{Position: pos},
+		uci.CmdGo{MoveTime: 50 * time.Millisecond},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := engine.ID()["name"]; name != "TestFish" {
+		t.Errorf("engine name %q", name)
+	}
+	if _, ok := engine.Options()["MultiPV"]; !ok {
+		t.Error("MultiPV option not reported")
+	}
+	results := engine.SearchResults()
+	if results.BestMove == nil {
+		t.Fatal("no best move")
+	}
+	got, want := chess.UCINotation{}.Encode(pos, results.BestMove), firstMove(t)
+	if got != want {
+		t.Errorf("best move %s, want the first legal move %s", got, want)
+	}
+	if results.Info.Depth != 3 || len(results.MultiPVInfo) != 2 {
+		t.Errorf("depth %d with %d lines, want 3 with 2", results.Info.Depth, len(results.MultiPVInfo))
+	}
+}
+
+func TestEngineFaults(t *testing.T) {
+	pos := chess.StartingPosition()
+	for _, tc := range []struct {
+		name      string
+		script    string
+		cmds      []uci.Cmd
+		wantAlive bool
+	}{
+		{"crash", "crash go\n", []uci.Cmd{uci.CmdUCI, uci.CmdPosition{Position: pos}, uci.CmdGo{MoveTime: 10 * time.Millisecond}}, false},
+		{"hang", "hang isready\n", []uci.Cmd{uci.CmdUCI, uci.CmdIsReady}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("alive needs signal 0")
+			}
+			engine := startEngine(t, tc.script)
+			defer kill(engine)
+			if err := run(engine, tc.cmds...); err != errTimeout {
+				t.Fatalf("run: %v, want %v", err, errTimeout)
+			}
+			if got := alive(engine); got != tc.wantAlive {
+				t.Errorf("engine alive: %t, want %t", got, tc.wantAlive)
+			}
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		engine := startEngine(t, "malformed go\n")
+		defer kill(engine)
+		err := run(engine, uci.CmdUCI, uci.CmdPosition{Position: pos}, uci.CmdGo{MoveTime: 10 * time.Millisecond})
+		if err == nil || err == errTimeout {
+			t.Fatalf("run: %v, want an error decoding the best move", err)
+		}
+		// The engine keeps going after garbage.
+		if err := run(engine, uci.CmdIsReady, uci.CmdGo{MoveTime: 10 * time.Millisecond}); err != nil {
+			t.Fatalf("second search: %v", err)
+		}
+		if engine.SearchResults().BestMove == nil {
+			t.Error("no best move from the second search")
+		}
+	})
+}
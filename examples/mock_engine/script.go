@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Script describes how the mock engine behaves. It is read from a plain
+// text file, one directive per line. Blank lines are ignored, and a #
+// starts a comment at the beginning of a line or after white space,
+// outside double quotes:
+//
+//	name MockFish 1.0               # "id name" reply
+//	author The Mock Authors         # "id author" reply
+//	option name Hash type spin default 16 min 1 max 1024
+//	delay 20ms                      # pause before each info line
+//	info depth 1 score cp 15 pv e2e4
+//	info depth 2 score cp 22 pv e2e4 e7e5
+//	bestmove e2e4 ponder e7e5       # reply to every "go" with this move
+//	bestmove auto                   # play the first legal move (the default)
+//	bestmove random                 # play a random legal move
+//	seed 42                         # seed for "bestmove random"
+//	crash go 3                      # exit with status 1 on the 3rd "go"
+//	hang isready                    # stop answering on the 1st "isready"
+//	malformed go 2                  # answer the 2nd "go" with garbage
+//
+// Every info line is sent for each "go". When no info lines are given the
+// engine makes up a short search ending in the move it is about to play.
+// Info lines may use {move} as a placeholder for that move.
+type Script struct {
+	Name     string
+	Author   string
+	Options  []string
+	Infos    []string
+	BestMove string
+	Ponder   string
+	Seed     int64
+	Delay    time.Duration
+	Faults   []Fault
+}
+
+// Fault makes the engine misbehave when it receives a command for the
+// Nth time.
+type Fault struct {
+	Kind    string // "crash", "hang" or "malformed"
+	Command string // the UCI command that triggers it, e.g. "go"
+	Count   int    // trigger on this occurrence, starting at 1
+}
+
+func defaultScript() *Script {
+	return &Script{
+		Name:     "MockEngine",
+		Author:   "chess examples",
+		BestMove: "auto",
+		Seed:     1,
+		Options: []string{
+			"option name Hash type spin default 16 min 1 max 1024",
+			"option name MultiPV type spin default 1 min 1 max 500",
+			"option name UCI_ShowWDL type check default false",
+			"option name Script type string default <empty>",
+		},
+	}
+}
+
+// ParseScript reads a script, starting from the default behaviour.
+func ParseScript(r io.Reader) (*Script, error) {
+	s := defaultScript()
+	customOptions := false
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+		directive, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		switch directive {
+		case "name":
+			s.Name = rest
+		case "author":
+			s.Author = rest
+		case "option":
+			if !customOptions {
+				s.Options = nil
+				customOptions = true
+			}
+			s.Options = append(s.Options, line)
+		case "info":
+			s.Infos = append(s.Infos, line)
+		case "bestmove":
+			move, ponder, _ := strings.Cut(rest, " ponder ")
+			s.BestMove = strings.TrimSpace(move)
+			s.Ponder = strings.TrimSpace(ponder)
+		case "seed":
+			n, err := strconv.ParseInt(rest, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			s.Seed = n
+		case "delay":
+			d, err := time.ParseDuration(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			s.Delay = d
+		case "crash", "hang", "malformed":
+			fault, err := parseFault(directive, rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			s.Faults = append(s.Faults, fault)
+		default:
+			return nil, fmt.Errorf("line %d: unknown directive %q", lineNum, directive)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// stripComment returns line without its comment and surrounding white
+// space.
+func stripComment(line string) string {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '#' && !quoted && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// loadScript reads the script file at path.
+func loadScript(path string) (*Script, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	script, err := ParseScript(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+func parseFault(kind, rest string) (Fault, error) {
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Fault{}, fmt.Errorf("%s needs a command and an optional count", kind)
+	}
+	fault := Fault{Kind: kind, Command: fields[0], Count: 1}
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return Fault{}, fmt.Errorf("%s: invalid count %q", kind, fields[1])
+		}
+		fault.Count = n
+	}
+	return fault, nil
+}
+
+// faultFor returns the fault triggered by the count-th occurrence of
+// command, if any.
+func (s *Script) faultFor(command string, count int) (Fault, bool) {
+	for _, f := range s.Faults {
+		if f.Command == command && f.Count == count {
+			return f, true
+		}
+	}
+	return Fault{}, false
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Cursor is a location in the move tree of a game. Unlike the game's own
+// current move, it can be placed anywhere in the tree directly: by ply, by
+// a path of child indices or by a SAN path such as
+// "1.e4 c5 2.Nf3 (2.c3 d5)".
+type Cursor struct {
+	game *chess.Game
+	move *chess.Move
+}
+
+// NewCursor returns a cursor at the starting position of game.
+func NewCursor(game *chess.Game) *Cursor {
+	return &Cursor{game: game, move: game.GetRootMove()}
+}
+
+// Move returns the move the cursor is on; at the start of the game it is
+// the root move, which has no parent.
+func (c *Cursor) Move() *chess.Move {
+	return c.move
+}
+
+// Position returns the position after the move the cursor is on.
+func (c *Cursor) Position() *chess.Position {
+	return c.move.Position()
+}
+
+// Ply returns the number of moves from the start of the game.
+func (c *Cursor) Ply() int {
+	ply := 0
+	for m := c.move; m.Parent() != nil; m = m.Parent() {
+		ply++
+	}
+	return ply
+}
+
+// Line returns the moves from the start of the game to the cursor.
+func (c *Cursor) Line() []*chess.Move {
+	var line []*chess.Move
+	for m := c.move; m.Parent() != nil; m = m.Parent() {
+		line = append(line, m)
+	}
+	for i, j := 0, len(line)-1; i < j; i, j = i+1, j-1 {
+		line[i], line[j] = line[j], line[i]
+	}
+	return line
+}
+
+// Forward moves to the first child of the current move.
+func (c *Cursor) Forward() bool {
+	return c.Child(0) == nil
+}
+
+// Back moves to the parent of the current move.
+func (c *Cursor) Back() bool {
+	if c.move.Parent() == nil {
+		return false
+	}
+	c.move = c.move.Parent()
+	return true
+}
+
+// Child moves to child i of the current move: 0 is the main continuation,
+// 1 and above the variations.
+func (c *Cursor) Child(i int) error {
+	children := c.move.Children()
+	if i < 0 || i >= len(children) {
+		return fmt.Errorf("move has %d continuations, no child %d", len(children), i)
+	}
+	c.move = children[i]
+	return nil
+}
+
+// ToPly moves to ply n of the current line: back to an earlier move, or
+// forward along the main continuation of the current move.
+func (c *Cursor) ToPly(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid ply %d", n)
+	}
+	ply := c.Ply()
+	m := c.move
+	for ; ply > n; ply-- {
+		m = m.Parent()
+	}
+	for ; ply < n; ply++ {
+		if len(m.Children()) == 0 {
+			return fmt.Errorf("line ends at ply %d", ply)
+		}
+		m = m.Children()[0]
+	}
+	c.move = m
+	return nil
+}
+
+// Path returns the child index of each move from the start of the game to
+// the cursor, e.g. [0 0 1 0] for 1.e4 c5 2.c3 d5 when 2.Nf3 is the main
+// line.
+func (c *Cursor) Path() []int {
+	line := c.Line()
+	path := make([]int, len(line))
+	for i, m := range line {
+		path[i] = indexOf(m.Parent().Children(), m)
+	}
+	return path
+}
+
+// ToPath moves to the move reached by following path from the start of the
+// game. The cursor is left unchanged when the path does not exist.
+func (c *Cursor) ToPath(path []int) error {
+	m := c.game.GetRootMove()
+	for ply, i := range path {
+		children := m.Children()
+		if i < 0 || i >= len(children) {
+			return fmt.Errorf("ply %d: move has %d continuations, no child %d", ply+1, len(children), i)
+		}
+		m = children[i]
+	}
+	c.move = m
+	return nil
+}
+
+// moveNumberPrefix matches the move number in front of a SAN move, as in
+// "12.", "12..." or "12…".
+var moveNumberPrefix = regexp.MustCompile(`^\d+(\.+|…)?`)
+
+// movetextComment matches a {brace} comment or a ; comment running to the
+// end of the line.
+var movetextComment = regexp.MustCompile(`\{[^}]*\}|;[^\n]*`)
+
+// ToSAN moves to the last move of a SAN path, read the way PGN movetext
+// is: "(" starts an alternative to the move before it and ")" goes back to
+// the line it interrupted. Comments, NAGs and result tokens are skipped,
+// so movetext copied from a PGN file works too. Every move must already be
+// in the tree. The cursor is left unchanged on error.
+func (c *Cursor) ToSAN(s string) error {
+	s = movetextComment.ReplaceAllString(s, " ")
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	current := c.game.GetRootMove()
+	last := current
+	var stack []*chess.Move
+	for _, token := range strings.Fields(s) {
+		switch token {
+		case "(":
+			if current.Parent() == nil {
+				return fmt.Errorf("variation without a move before it")
+			}
+			stack = append(stack, current)
+			current = current.Parent()
+			continue
+		case ")":
+			if len(stack) == 0 {
+				return fmt.Errorf("unbalanced )")
+			}
+			current = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			continue
+		case "*", "1-0", "0-1", "1/2-1/2":
+			continue
+		}
+		san := strings.TrimRight(moveNumberPrefix.ReplaceAllString(token, ""), "!?")
+		if san == "" || strings.HasPrefix(san, "$") {
+			continue
+		}
+		next, err := findChild(current, san)
+		if err != nil {
+			return err
+		}
+		current, last = next, next
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unbalanced (")
+	}
+	c.move = last
+	return nil
+}
+
+// findChild returns the child of parent played with the SAN move san.
+func findChild(parent *chess.Move, san string) (*chess.Move, error) {
+	pos := parent.Position()
+	m, err := chess.AlgebraicNotation{}.Decode(pos, san)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %w", moveNumber(pos), san, err)
+	}
+	for _, child := range parent.Children() {
+		if child.S1() == m.S1() && child.S2() == m.S2() && child.Promo() == m.Promo() {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("%s%s is not in the tree", moveNumber(pos), san)
+}
+
+// String returns the SAN path to the cursor. A move that is not the main
+// continuation is written as a variation of it, so the path reads as
+// movetext: "1.e4 c5 2.Nf3 (2.c3 d5)". ToSAN accepts it back.
+func (c *Cursor) String() string {
+	var parts []string
+	depth := 0
+	numbered := true // the next move needs its number, even for Black
+	for _, m := range c.Line() {
+		if main := m.Parent().Children()[0]; main != m {
+			parts = append(parts, sanWithNumber(main, numbered))
+			parts = append(parts, "("+sanWithNumber(m, true))
+			depth++
+		} else {
+			parts = append(parts, sanWithNumber(m, numbered))
+		}
+		numbered = false
+	}
+	return strings.Join(parts, " ") + strings.Repeat(")", depth)
+}
+
+// sanWithNumber returns the SAN of m, with its move number before White's
+// moves, and before Black's when numbered is set.
+func sanWithNumber(m *chess.Move, numbered bool) string {
+	before := m.Parent().Position()
+	san := chess.AlgebraicNotation{}.Encode(before, m)
+	if before.Turn() == chess.White || numbered {
+		return strings.TrimSpace(moveNumber(before)) + san
+	}
+	return san
+}
+
+// moveNumber returns the number written before a move from pos: "12. "
+// for White, "12... " for Black.
+func moveNumber(pos *chess.Position) string {
+	n := "1"
+	if fields := strings.Fields(pos.String()); len(fields) >= 6 {
+		n = fields[5]
+	}
+	if pos.Turn() == chess.White {
+		return n + ". "
+	}
+	return n + "... "
+}
+
+// Apply makes the cursor's move the current move of the game, so that
+// Game.Position, PushMove and the game's own navigation start from there.
+// Like GoForward, it leaves the outcome of the game alone: playing the
+// moves again would end the game on any checkmate along the way.
+func (c *Cursor) Apply() error {
+	for c.game.GoBack() {
+	}
+	for _, m := range c.Line() {
+		// GoForward only follows main continuations, so m is made the
+		// main one for the step and put back after it.
+		siblings := m.Parent().Children()
+		i := indexOf(siblings, m)
+		if i < 0 {
+			return fmt.Errorf("%s is not in the game", sanWithNumber(m, true))
+		}
+		siblings[0], siblings[i] = siblings[i], siblings[0]
+		c.game.GoForward()
+		siblings[0], siblings[i] = siblings[i], siblings[0]
+	}
+	return nil
+}
+
+func indexOf(moves []*chess.Move, m *chess.Move) int {
+	for i, c := range moves {
+		if c == m {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// DOTOptions limits and shapes the graph written by WriteDOT.
+type DOTOptions struct {
+	// MaxDepth is the number of moves drawn from the start; 0 draws all.
+	MaxDepth int
+	// MaxWidth is the number of continuations drawn after a move, main
+	// continuation first; 0 draws all.
+	MaxWidth int
+	// CommentLength is the number of characters of a comment shown on its
+	// move; 0 uses DefaultCommentLength and a negative value hides them.
+	CommentLength int
+}
+
+// DefaultCommentLength is the length comments are cut to by default.
+const DefaultCommentLength = 40
+
+// WriteDOT writes the move tree of game as a Graphviz DOT graph, one node
+// per move labelled with its SAN, NAG glyph and the start of its comment.
+// Main line moves are filled and joined by bold edges. Where a limit cuts
+// the tree, a dotted node tells how many moves were left out. Render it
+// with e.g. "dot -Tsvg tree.dot -o tree.svg".
+func WriteDOT(w io.Writer, game *chess.Game, opts DOTOptions) error {
+	if opts.CommentLength == 0 {
+		opts.CommentLength = DefaultCommentLength
+	}
+	var sb strings.Builder
+	sb.WriteString("digraph moves {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString(`  node [shape=box, style="rounded", fontname="Helvetica", fontsize=11];` + "\n")
+	sb.WriteString(`  edge [color="#777777", arrowsize=0.6];` + "\n")
+
+	root := game.GetRootMove()
+	ids := map[*chess.Move]string{root: "start"}
+	label := "Start"
+	if c := comment(root, opts.CommentLength); c != "" {
+		label += "\n" + c
+	}
+	fmt.Fprintf(&sb, "  start [label=%s, style=\"rounded,filled\", fillcolor=\"#dddddd\"];\n", quote(label))
+
+	var walk func(m *chess.Move, depth int, mainLine bool)
+	walk = func(m *chess.Move, depth int, mainLine bool) {
+		children := m.Children()
+		shown := children
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			shown = nil
+		} else if opts.MaxWidth > 0 && len(shown) > opts.MaxWidth {
+			shown = shown[:opts.MaxWidth]
+		}
+		for i, child := range shown {
+			id := fmt.Sprintf("m%d", len(ids))
+			ids[child] = id
+			main := mainLine && i == 0
+			// Every node carries its move number, as it is read on its own.
+			label := sanWithNumber(child, true) + nagSymbol(child.NAG())
+			if c := comment(child, opts.CommentLength); c != "" {
+				label += "\n" + c
+			}
+			if main {
+				fmt.Fprintf(&sb, "  %s [label=%s, style=\"rounded,filled\", fillcolor=\"#dddddd\"];\n", id, quote(label))
+				fmt.Fprintf(&sb, "  %s -> %s [color=\"#000000\", penwidth=2.5];\n", ids[m], id)
+			} else {
+				fmt.Fprintf(&sb, "  %s [label=%s];\n", id, quote(label))
+				fmt.Fprintf(&sb, "  %s -> %s;\n", ids[m], id)
+			}
+			walk(child, depth+1, main)
+		}
+		if hidden := countMoves(children) - countMoves(shown); hidden > 0 {
+			more := fmt.Sprintf("+%d moves", hidden)
+			if hidden == 1 {
+				more = "+1 move"
+			}
+			fmt.Fprintf(&sb, "  %s_more [label=%s, style=dotted, fontcolor=\"#777777\"];\n", ids[m], quote(more))
+			fmt.Fprintf(&sb, "  %s -> %s_more [style=dotted];\n", ids[m], ids[m])
+		}
+	}
+	walk(root, 0, true)
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// countMoves returns the number of moves in the subtrees of moves.
+func countMoves(moves []*chess.Move) int {
+	n := len(moves)
+	for _, m := range moves {
+		n += countMoves(m.Children())
+	}
+	return n
+}
+
+// comment returns the comment of m cut to n characters, or nothing when n
+// is negative.
+func comment(m *chess.Move, n int) string {
+	c := strings.Join(strings.Fields(m.Comments()), " ")
+	if n < 0 || c == "" {
+		return ""
+	}
+	if r := []rune(c); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return c
+}
+
+// quote returns s as a DOT string, with newlines as line breaks.
+func quote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// nagSymbols are the usual symbols of the common NAGs.
+var nagSymbols = map[string]string{
+	"$1": "!", "$2": "?", "$3": "!!", "$4": "??", "$5": "!?", "$6": "?!",
+	"$10": " =", "$13": " ∞", "$14": " ⩲", "$15": " ⩱", "$16": " ±", "$17": " ∓",
+	"$18": " +−", "$19": " −+",
+}
+
+func nagSymbol(nag string) string {
+	if nag == "" {
+		return ""
+	}
+	if s, ok := nagSymbols[nag]; ok {
+		return s
+	}
+	if strings.HasPrefix(nag, "$") {
+		return " " + nag
+	}
+	return nag
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// The operations below edit the tree around the cursor and leave both the
+// cursor and the game's current move on a move that is still in the tree.
+// Reordering is done in place, in the slice Move.Children returns. Taking
+// moves out is not possible in place, so the move whose continuations
+// change is replaced by a copy holding the ones kept; moves taken from the
+// tree before a deletion may no longer be part of it. After every edit the
+// outcome of the game is settled again, see settleOutcome.
+
+// Delete removes the move at the cursor and every move after it. The
+// cursor goes back to the move before.
+func (c *Cursor) Delete() error {
+	parent := c.move.Parent()
+	if parent == nil {
+		return fmt.Errorf("the starting position cannot be deleted")
+	}
+	var kept []*chess.Move
+	for _, m := range parent.Children() {
+		if m != c.move {
+			kept = append(kept, m)
+		}
+	}
+	return c.editTree(func() error {
+		parent, err := setChildren(c.game, parent, kept)
+		if err != nil {
+			return err
+		}
+		c.move = parent
+		return nil
+	})
+}
+
+// Truncate removes every move after the cursor, variations included.
+func (c *Cursor) Truncate() error {
+	return c.editTree(func() error {
+		m, err := setChildren(c.game, c.move, nil)
+		if err != nil {
+			return err
+		}
+		c.move = m
+		return nil
+	})
+}
+
+// StripVariations removes every variation of the game, keeping its main
+// line. A cursor in a variation goes back to where it left the main line.
+func (c *Cursor) StripVariations() error {
+	onMainLine := map[*chess.Move]bool{}
+	for _, m := range c.game.Moves() {
+		onMainLine[m] = true
+	}
+	for c.move.Parent() != nil && !onMainLine[c.move] {
+		c.move = c.move.Parent()
+	}
+	return c.editTree(func() error {
+		// From the end of the main line back to the start, so each move is
+		// replaced before its parent is.
+		line := append([]*chess.Move{c.game.GetRootMove()}, c.game.Moves()...)
+		for i := len(line) - 1; i >= 0; i-- {
+			children := line[i].Children()
+			if len(children) <= 1 {
+				continue
+			}
+			m, err := setChildren(c.game, line[i], children[:1])
+			if err != nil {
+				return err
+			}
+			if c.move == line[i] {
+				c.move = m
+			}
+		}
+		return nil
+	})
+}
+
+// Promote moves the variation the cursor is in one place up among the
+// continuations of the move it starts from. A variation placed first
+// becomes the main continuation there.
+func (c *Cursor) Promote() error {
+	start := c.variationStart()
+	if start == nil {
+		return fmt.Errorf("the cursor is on the main line")
+	}
+	return c.editTree(func() error {
+		siblings := start.Parent().Children()
+		i := indexOf(siblings, start)
+		siblings[i-1], siblings[i] = siblings[i], siblings[i-1]
+		return nil
+	})
+}
+
+// PromoteToMainLine makes the line through the cursor the main line of
+// the game, as PushMoveOptions.ForceMainline does for a single move.
+func (c *Cursor) PromoteToMainLine() error {
+	return c.editTree(func() error {
+		for _, m := range c.Line() {
+			siblings := m.Parent().Children()
+			i := indexOf(siblings, m)
+			copy(siblings[1:i+1], siblings[:i])
+			siblings[0] = m
+		}
+		return nil
+	})
+}
+
+// Demote moves the line through the cursor one place down among the
+// continuations of the last move where it has alternatives; on the main
+// line, the first variation there becomes the main continuation.
+func (c *Cursor) Demote() error {
+	for m := c.move; m.Parent() != nil; m = m.Parent() {
+		siblings := m.Parent().Children()
+		if len(siblings) < 2 {
+			continue
+		}
+		i := indexOf(siblings, m)
+		if i == len(siblings)-1 {
+			return fmt.Errorf("%s is already the last continuation", sanWithNumber(m, true))
+		}
+		return c.editTree(func() error {
+			siblings[i], siblings[i+1] = siblings[i+1], siblings[i]
+			return nil
+		})
+	}
+	return fmt.Errorf("the line has no alternatives to demote it below")
+}
+
+// variationStart returns the first move of the innermost variation the
+// cursor is in, or nil on the main continuation of every move.
+func (c *Cursor) variationStart() *chess.Move {
+	for m := c.move; m.Parent() != nil; m = m.Parent() {
+		if m.Parent().Children()[0] != m {
+			return m
+		}
+	}
+	return nil
+}
+
+// editTree makes a change to the tree with edit, then settles the outcome
+// the game had before it and puts the game on the cursor.
+func (c *Cursor) editTree(edit func() error) error {
+	outcome, method := c.game.Outcome(), c.game.Method()
+	if err := edit(); err != nil {
+		return err
+	}
+	return settleOutcome(c, outcome, method)
+}
+
+// settleOutcome sets the outcome of the game after a tree edit, given the
+// one it had before. A resignation, an agreed or claimed draw, or a result
+// read from PGN without the moves deciding it stands. Otherwise the
+// outcome is worked out again from the end of the main line: the game
+// plays its moves without ever taking an outcome back, so a deleted
+// checkmate would still end it, and a mate promoted to the main line
+// would not.
+func settleOutcome(c *Cursor, outcome chess.Outcome, method chess.Method) error {
+	if standing(outcome, method) {
+		return setOutcome(c, outcome, method)
+	}
+	if c.game.Outcome() != chess.NoOutcome || c.game.Method() != chess.NoMethod {
+		if err := resetOutcome(c, chess.NoOutcome); err != nil {
+			return err
+		}
+	}
+	if err := playLastMove(c.game); err != nil {
+		return err
+	}
+	syncResultTag(c.game)
+	return c.Apply()
+}
+
+// standing reports whether an outcome was decided away from the board,
+// so that no edit of the moves changes it.
+func standing(outcome chess.Outcome, method chess.Method) bool {
+	switch method {
+	case chess.Resignation, chess.DrawOffer, chess.ThreefoldRepetition, chess.FiftyMoveRule:
+		return true
+	case chess.NoMethod:
+		return outcome != chess.NoOutcome
+	}
+	return false
+}
+
+// setOutcome gives the game exactly outcome and method, as far as the
+// game allows: a checkmate or automatic draw is reached again by playing
+// the last move of the main line, and a claimed draw is claimed again at
+// its end.
+func setOutcome(c *Cursor, outcome chess.Outcome, method chess.Method) error {
+	game := c.game
+	if game.Outcome() != outcome || game.Method() != method {
+		token := chess.NoOutcome
+		if method == chess.NoMethod {
+			token = outcome
+		}
+		if err := resetOutcome(c, token); err != nil {
+			return err
+		}
+		switch {
+		// A game read from PGN may have a method and yet no outcome, as
+		// "*" after a checkmate.
+		case method == chess.NoMethod || outcome == chess.NoOutcome:
+		case method == chess.Resignation:
+			if outcome == chess.WhiteWon {
+				game.Resign(chess.Black)
+			} else {
+				game.Resign(chess.White)
+			}
+		case method == chess.DrawOffer || method == chess.ThreefoldRepetition || method == chess.FiftyMoveRule:
+			end := &Cursor{game: game, move: game.GetRootMove()}
+			for end.Forward() {
+			}
+			if err := end.Apply(); err != nil {
+				return err
+			}
+			// A claim the moves no longer support lapses.
+			_ = game.Draw(method)
+		default:
+			if err := playLastMove(game); err != nil {
+				return err
+			}
+		}
+	}
+	syncResultTag(game)
+	return c.Apply()
+}
+
+// resetOutcome takes the outcome of the game back to outcome, with no
+// method, keeping its moves. Only a game read from PGN starts without the
+// outcome its moves reached, so the game is reset as in resetGame and its
+// moves are put back on the new starting position.
+func resetOutcome(c *Cursor, outcome chess.Outcome) error {
+	root := c.game.GetRootMove()
+	children := root.Children()
+	if err := resetGame(c.game, outcome); err != nil {
+		return err
+	}
+	for _, child := range children {
+		c.game.AddVariation(c.game.GetRootMove(), child)
+	}
+	if c.move == root {
+		c.move = c.game.GetRootMove()
+	}
+	return nil
+}
+
+// playLastMove plays the last move of the main line again, so that the
+// game works out whether it ends the game.
+func playLastMove(game *chess.Game) error {
+	moves := game.Moves()
+	if len(moves) == 0 {
+		return nil
+	}
+	last := moves[len(moves)-1]
+	at := &Cursor{game: game, move: last.Parent()}
+	if err := at.Apply(); err != nil {
+		return err
+	}
+	// Playing a move found among the continuations moves onto it without
+	// adding it again.
+	return game.Move(last, nil)
+}
+
+// syncResultTag keeps the Result tag, when the game has one, in step with
+// its outcome.
+func syncResultTag(game *chess.Game) {
+	if game.GetTagPair("Result") != "" {
+		game.AddTagPair("Result", game.Outcome().String())
+	}
+}
+
+// setChildren makes children, some of the continuations of m, its only
+// continuations and returns the move now standing for m. A copy of m
+// takes its place in the tree, as the slice of continuations cannot
+// shrink. The starting position has no place to put a copy in, so the
+// game is reset from its own PGN tags instead. The outcome of the game is
+// left for the caller to settle.
+func setChildren(game *chess.Game, m *chess.Move, children []*chess.Move) (*chess.Move, error) {
+	parent := m.Parent()
+	if parent == nil {
+		if err := resetGame(game, game.Outcome()); err != nil {
+			return nil, err
+		}
+		root := game.GetRootMove()
+		for _, child := range children {
+			game.AddVariation(root, child)
+		}
+		return root, nil
+	}
+
+	replacement := m.Clone()
+	for _, child := range children {
+		game.AddVariation(replacement, child)
+	}
+	siblings := parent.Children()
+	siblings[indexOf(siblings, m)] = replacement
+	// Playing a move found among the continuations links it to its parent
+	// without adding it again.
+	at := &Cursor{game: game, move: parent}
+	if err := at.Apply(); err != nil {
+		return nil, err
+	}
+	if err := game.Move(replacement, nil); err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// resetGame empties the move tree of game, keeping its tags, starting
+// position and the comment before the first move, and gives it outcome
+// with no method. The game is parsed again from its own PGN tags, the one
+// way to take back the outcome of a game.
+func resetGame(game *chess.Game, outcome chess.Outcome) error {
+	root := game.GetRootMove()
+	var header string
+	if s := game.String(); strings.HasPrefix(s, "[") {
+		header, _, _ = strings.Cut(s, "\n\n")
+	}
+	start := root.Position()
+	setUp := game.GetTagPair("FEN") == "" && !samePosition(start, chess.StartingPosition())
+	if setUp {
+		header += fmt.Sprintf("\n[SetUp \"1\"]\n[FEN \"%s\"]", start)
+	}
+	// The PGN parser needs at least one tag to find the game.
+	untagged := header == ""
+	if untagged {
+		header = `[Event "?"]`
+	}
+	opt, err := chess.PGN(strings.NewReader(header + "\n\n" + outcome.String()))
+	if err != nil {
+		return err
+	}
+	opt(game)
+	if untagged {
+		game.RemoveTagPair("Event")
+	}
+	if setUp {
+		game.RemoveTagPair("SetUp")
+		game.RemoveTagPair("FEN")
+	}
+	game.GetRootMove().SetComment(root.Comments())
+	game.GetRootMove().SetNAG(root.NAG())
+	// The parser does not read a drawn result from movetext.
+	if outcome == chess.Draw && game.Outcome() != chess.Draw {
+		return game.Draw(chess.DrawOffer)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Graph is the move tree of a game with transpositions collapsed: moves
+// of the tree reaching the same position share a node, whatever the move
+// order. Nodes are keyed by Position.Hash together with the ply. The ply
+// keeps a position repeated within a line from closing a cycle, so the
+// graph is a DAG; positions reached after a different number of moves,
+// which needs a lost tempo, stay apart.
+type Graph struct {
+	Root *Node
+	// Nodes are in the order they were found, a walk of the tree main
+	// line first, so every edge goes from a node to a later one.
+	Nodes []*Node
+	game  *chess.Game
+	index map[nodeKey]*Node
+}
+
+type nodeKey struct {
+	hash [16]byte
+	ply  int
+}
+
+// Node is a position of a Graph.
+type Node struct {
+	ID       int
+	Ply      int
+	Position *chess.Position
+	// Moves are the moves of the tree reaching the position, the root
+	// move for the starting position.
+	Moves []*chess.Move
+	In    []*Edge
+	Out   []*Edge
+}
+
+// Edge is a move between two positions of a Graph.
+type Edge struct {
+	From, To *Node
+	SAN      string
+	// Moves are the moves of the tree this edge stands for.
+	Moves []*chess.Move
+}
+
+// NewGraph builds the graph of the move tree of game.
+func NewGraph(game *chess.Game) *Graph {
+	g := &Graph{game: game, index: map[nodeKey]*Node{}}
+	root := game.GetRootMove()
+	g.Root = g.node(root, 0)
+	var walk func(m *chess.Move, from *Node)
+	walk = func(m *chess.Move, from *Node) {
+		for _, child := range m.Children() {
+			to := g.node(child, from.Ply+1)
+			g.edge(from, to, chess.AlgebraicNotation{}.Encode(m.Position(), child), child)
+			walk(child, to)
+		}
+	}
+	walk(root, g.Root)
+	return g
+}
+
+// node returns the node of the position after m, adding m to its moves.
+func (g *Graph) node(m *chess.Move, ply int) *Node {
+	key := nodeKey{positionKey(m.Position()), ply}
+	n, ok := g.index[key]
+	if !ok {
+		n = &Node{ID: len(g.Nodes), Ply: ply, Position: m.Position()}
+		g.index[key] = n
+		g.Nodes = append(g.Nodes, n)
+	}
+	n.Moves = append(n.Moves, m)
+	return n
+}
+
+func (g *Graph) edge(from, to *Node, san string, m *chess.Move) {
+	for _, e := range from.Out {
+		if e.To == to {
+			e.Moves = append(e.Moves, m)
+			return
+		}
+	}
+	e := &Edge{From: from, To: to, SAN: san, Moves: []*chess.Move{m}}
+	from.Out = append(from.Out, e)
+	to.In = append(to.In, e)
+}
+
+// positionKey returns the Position.Hash of pos with the move counters set
+// to zero and the en passant square dropped when no capture can use it.
+// The hash of pos itself covers both, so the same position reached by two
+// move orders would hash differently.
+func positionKey(pos *chess.Position) [16]byte {
+	fields := strings.Fields(pos.String())
+	if len(fields) < 4 {
+		return pos.Hash()
+	}
+	ep := "-"
+	for _, m := range pos.ValidMoves() {
+		if m.HasTag(chess.EnPassant) {
+			ep = fields[3]
+			break
+		}
+	}
+	fen, err := chess.FEN(strings.Join(append(fields[:3:3], ep, "0", "1"), " "))
+	if err != nil {
+		return pos.Hash()
+	}
+	return chess.NewGame(fen).Position().Hash()
+}
+
+// Transpositions returns the nodes reached from more than one position,
+// that is where different move orders meet. The Moves of each node are
+// the tree moves that transpose into each other.
+func (g *Graph) Transpositions() []*Node {
+	var nodes []*Node
+	for _, n := range g.Nodes {
+		if len(n.In) > 1 {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Path returns the SAN path of a tree move, as Cursor.String writes it.
+func (g *Graph) Path(m *chess.Move) string {
+	c := NewCursor(g.game)
+	c.move = m
+	return c.String()
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+type jsonNode struct {
+	ID    int      `json:"id"`
+	Ply   int      `json:"ply"`
+	FEN   string   `json:"fen"`
+	Hash  string   `json:"hash"`
+	Paths []string `json:"paths"`
+}
+
+type jsonEdge struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	SAN   string `json:"san"`
+	Count int    `json:"count"`
+}
+
+// WriteJSON writes the graph as JSON: the nodes with their FEN, key hash
+// and the SAN paths of the tree moves reaching them, and the edges with
+// the number of tree moves each stands for.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	var out jsonGraph
+	for _, n := range g.Nodes {
+		key := positionKey(n.Position)
+		jn := jsonNode{ID: n.ID, Ply: n.Ply, FEN: n.Position.String(), Hash: hex.EncodeToString(key[:])}
+		for _, m := range n.Moves {
+			jn.Paths = append(jn.Paths, g.Path(m))
+		}
+		out.Nodes = append(out.Nodes, jn)
+		for _, e := range n.Out {
+			out.Edges = append(out.Edges, jsonEdge{From: e.From.ID, To: e.To.ID, SAN: e.SAN, Count: len(e.Moves)})
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
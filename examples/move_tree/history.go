@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Edit is one change made to a game through an Editor. Locations are SAN
+// paths, as written by Cursor.String, so a log stays readable and can be
+// replayed on a fresh copy of the game.
+type Edit struct {
+	// Op is "push", "variation", "comment", "nag", "resign", or one of the
+	// tree edits "delete", "truncate", "strip", "promote", "promote-main"
+	// and "demote".
+	Op string `json:"op"`
+	// At is the move the edit applies to, or after which a move is played.
+	At string `json:"at"`
+	// Move is the SAN of the move played by "push" and "variation".
+	Move string `json:"move,omitempty"`
+	// Mainline makes the move of a "push" the main continuation, as
+	// PushMoveOptions.ForceMainline does; for a move already in the tree
+	// this promotes it.
+	Mainline bool   `json:"mainline,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	NAG      string `json:"nag,omitempty"`
+	// Color is the side resigning, "white" or "black".
+	Color string `json:"color,omitempty"`
+}
+
+func (e Edit) String() string {
+	switch e.Op {
+	case "push", "variation":
+		s := fmt.Sprintf("%s %s after %q", e.Op, e.Move, e.At)
+		if e.Mainline {
+			s += " as main line"
+		}
+		return s
+	case "comment":
+		return fmt.Sprintf("comment %q on %q", e.Comment, e.At)
+	case "nag":
+		return fmt.Sprintf("nag %s on %q", e.NAG, e.At)
+	case "resign":
+		return e.Color + " resigns"
+	case "strip":
+		return "strip variations"
+	}
+	return fmt.Sprintf("%s at %q", e.Op, e.At)
+}
+
+// apply makes the edit on game and returns the cursor left after it: on
+// the move played by "push", on At otherwise.
+func (e Edit) apply(game *chess.Game) (*Cursor, error) {
+	c := NewCursor(game)
+	if err := c.ToSAN(e.At); err != nil {
+		return nil, fmt.Errorf("%s: %w", e, err)
+	}
+	if err := c.Apply(); err != nil {
+		return nil, fmt.Errorf("%s: %w", e, err)
+	}
+	switch e.Op {
+	case "push", "variation":
+		opts := &chess.PushMoveOptions{ForceMainline: e.Mainline}
+		if err := game.PushMove(e.Move, opts); err != nil {
+			return nil, fmt.Errorf("%s: %w", e, err)
+		}
+		if e.Op == "variation" {
+			game.GoBack()
+			return c, nil
+		}
+		next, err := findChild(c.Move(), e.Move)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e, err)
+		}
+		// When the move was already in the tree, the game is left on a
+		// copy of it; put it back on the move in the tree.
+		c.move = next
+		if err := c.Apply(); err != nil {
+			return nil, fmt.Errorf("%s: %w", e, err)
+		}
+	case "comment":
+		c.Move().SetComment(e.Comment)
+	case "nag":
+		c.Move().SetNAG(e.NAG)
+	case "resign":
+		switch e.Color {
+		case "white":
+			game.Resign(chess.White)
+		case "black":
+			game.Resign(chess.Black)
+		default:
+			return nil, fmt.Errorf("%s: invalid color %q", e, e.Color)
+		}
+	case "delete", "truncate", "strip", "promote", "promote-main", "demote":
+		if err := treeEdits[e.Op](c); err != nil {
+			return nil, fmt.Errorf("%s: %w", e, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown edit %q", e.Op)
+	}
+	return c, nil
+}
+
+// treeEdits are the Cursor methods behind the tree edits.
+var treeEdits = map[string]func(*Cursor) error{
+	"delete":       (*Cursor).Delete,
+	"truncate":     (*Cursor).Truncate,
+	"strip":        (*Cursor).StripVariations,
+	"promote":      (*Cursor).Promote,
+	"promote-main": (*Cursor).PromoteToMainLine,
+	"demote":       (*Cursor).Demote,
+}
+
+// Editor edits a game through a log of edits that can be undone and redone
+// without limit. Before each edit it notes what the edit may change: the
+// continuations of every move, the comment and NAG of the move edited, and
+// the outcome. Undo puts those back on the same game. Moves keep their
+// identity, except where an edit or its undo takes continuations away from
+// a move, which is then replaced by a copy as Cursor.Delete explains.
+type Editor struct {
+	game   *chess.Game
+	cursor *Cursor
+	done   []Edit
+	before []snapshot
+	undone []Edit
+}
+
+// snapshot is the state of a game an edit may change.
+type snapshot struct {
+	root     *chess.Move
+	children map[*chess.Move][]*chess.Move
+	comment  string
+	nag      string
+	outcome  chess.Outcome
+	method   chess.Method
+	result   string
+}
+
+// takeSnapshot notes the state of game before edit.
+func takeSnapshot(game *chess.Game, edit Edit) snapshot {
+	s := snapshot{
+		root:     game.GetRootMove(),
+		children: map[*chess.Move][]*chess.Move{},
+		outcome:  game.Outcome(),
+		method:   game.Method(),
+		result:   game.GetTagPair("Result"),
+	}
+	var walk func(m *chess.Move)
+	walk = func(m *chess.Move) {
+		s.children[m] = append([]*chess.Move(nil), m.Children()...)
+		for _, child := range m.Children() {
+			walk(child)
+		}
+	}
+	walk(s.root)
+	c := NewCursor(game)
+	if c.ToSAN(edit.At) == nil {
+		s.comment, s.nag = c.Move().Comments(), c.Move().NAG()
+	}
+	return s
+}
+
+// restore puts game back in the state s noted, with the cursor at.
+func (s snapshot) restore(game *chess.Game, at string) (*Cursor, error) {
+	var restore func(m *chess.Move, want []*chess.Move) error
+	restore = func(m *chess.Move, want []*chess.Move) error {
+		if children := m.Children(); len(children) == len(want) {
+			copy(children, want)
+		} else {
+			var err error
+			if m, err = setChildren(game, m, want); err != nil {
+				return err
+			}
+		}
+		for _, child := range want {
+			if child.Parent() != m {
+				// Playing a move found among the continuations links it
+				// to its parent without adding it again.
+				if err := (&Cursor{game: game, move: m}).Apply(); err != nil {
+					return err
+				}
+				if err := game.Move(child, nil); err != nil {
+					return err
+				}
+			}
+			if err := restore(child, s.children[child]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := restore(game.GetRootMove(), s.children[s.root]); err != nil {
+		return nil, err
+	}
+
+	c := NewCursor(game)
+	if err := c.ToSAN(at); err != nil {
+		return nil, err
+	}
+	c.Move().SetComment(s.comment)
+	c.Move().SetNAG(s.nag)
+	if err := setOutcome(c, s.outcome, s.method); err != nil {
+		return nil, err
+	}
+	if s.result != "" {
+		game.AddTagPair("Result", s.result)
+	} else {
+		game.RemoveTagPair("Result")
+	}
+	return c, nil
+}
+
+// NewEditor returns an editor for game, with its cursor at the start. The
+// game is edited in place.
+func NewEditor(game *chess.Game) *Editor {
+	return &Editor{game: game, cursor: NewCursor(game)}
+}
+
+// Game returns the game being edited.
+func (e *Editor) Game() *chess.Game {
+	return e.game
+}
+
+// Cursor returns the location edits apply to. Moving it is not an edit.
+func (e *Editor) Cursor() *Cursor {
+	return e.cursor
+}
+
+// Log returns the edits made so far, not counting undone ones.
+func (e *Editor) Log() []Edit {
+	return append([]Edit(nil), e.done...)
+}
+
+// PushMove plays a move after the cursor and moves the cursor onto it.
+// With ForceMainline the move becomes the main continuation, also when it
+// is already in the tree.
+func (e *Editor) PushMove(san string, opts *chess.PushMoveOptions) error {
+	edit := Edit{Op: "push", At: e.cursor.String(), Move: san}
+	if opts != nil {
+		edit.Mainline = opts.ForceMainline
+	}
+	return e.do(edit)
+}
+
+// AddVariation adds a move after the cursor as the last continuation,
+// leaving the cursor where it is.
+func (e *Editor) AddVariation(san string) error {
+	return e.do(Edit{Op: "variation", At: e.cursor.String(), Move: san})
+}
+
+// SetComment replaces the comment of the move at the cursor.
+func (e *Editor) SetComment(comment string) error {
+	return e.do(Edit{Op: "comment", At: e.cursor.String(), Comment: comment})
+}
+
+// SetNAG replaces the NAG of the move at the cursor, e.g. "$1".
+func (e *Editor) SetNAG(nag string) error {
+	return e.do(Edit{Op: "nag", At: e.cursor.String(), NAG: nag})
+}
+
+// Resign ends the game with color resigning.
+func (e *Editor) Resign(color chess.Color) error {
+	return e.do(Edit{Op: "resign", At: e.cursor.String(), Color: strings.ToLower(color.Name())})
+}
+
+// Delete removes the move at the cursor and the moves after it.
+func (e *Editor) Delete() error {
+	return e.do(Edit{Op: "delete", At: e.cursor.String()})
+}
+
+// Truncate removes the moves after the cursor.
+func (e *Editor) Truncate() error {
+	return e.do(Edit{Op: "truncate", At: e.cursor.String()})
+}
+
+// StripVariations keeps only the main line of the game.
+func (e *Editor) StripVariations() error {
+	return e.do(Edit{Op: "strip", At: e.cursor.String()})
+}
+
+// Promote moves the variation at the cursor one place up.
+func (e *Editor) Promote() error {
+	return e.do(Edit{Op: "promote", At: e.cursor.String()})
+}
+
+// PromoteToMainLine makes the line through the cursor the main line.
+func (e *Editor) PromoteToMainLine() error {
+	return e.do(Edit{Op: "promote-main", At: e.cursor.String()})
+}
+
+// Demote moves the line through the cursor one place down.
+func (e *Editor) Demote() error {
+	return e.do(Edit{Op: "demote", At: e.cursor.String()})
+}
+
+// do applies a new edit and clears the edits that could be redone.
+func (e *Editor) do(edit Edit) error {
+	if err := e.apply(edit); err != nil {
+		return err
+	}
+	e.undone = nil
+	return nil
+}
+
+// apply makes edit, noting first what it changes.
+func (e *Editor) apply(edit Edit) error {
+	before := takeSnapshot(e.game, edit)
+	c, err := edit.apply(e.game)
+	if err != nil {
+		return err
+	}
+	e.cursor = c
+	e.done = append(e.done, edit)
+	e.before = append(e.before, before)
+	return nil
+}
+
+// Undo reverts the last edit and puts the cursor back where it was made.
+// It reports false when there is nothing to undo.
+func (e *Editor) Undo() (bool, error) {
+	n := len(e.done)
+	if n == 0 {
+		return false, nil
+	}
+	last := e.done[n-1]
+	c, err := e.before[n-1].restore(e.game, last.At)
+	if err != nil {
+		return false, fmt.Errorf("undo %s: %w", last, err)
+	}
+	e.cursor = c
+	e.done, e.before = e.done[:n-1], e.before[:n-1]
+	e.undone = append(e.undone, last)
+	return true, nil
+}
+
+// Redo makes the last undone edit again. It reports false when there is
+// nothing to redo.
+func (e *Editor) Redo() (bool, error) {
+	if len(e.undone) == 0 {
+		return false, nil
+	}
+	edit := e.undone[len(e.undone)-1]
+	if err := e.apply(edit); err != nil {
+		return false, err
+	}
+	e.undone = e.undone[:len(e.undone)-1]
+	return true, nil
+}
+
+// WriteLog writes edits as JSON, one edit per line.
+func WriteLog(w io.Writer, edits []Edit) error {
+	enc := json.NewEncoder(w)
+	for _, edit := range edits {
+		if err := enc.Encode(edit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadLog reads edits written by WriteLog. Blank lines are skipped.
+func ReadLog(r io.Reader) ([]Edit, error) {
+	var edits []Edit
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var edit Edit
+		if err := json.Unmarshal([]byte(text), &edit); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		edits = append(edits, edit)
+	}
+	return edits, scanner.Err()
+}
+
+// Replay returns an editor for game with edits made, in order, so that
+// they can be undone.
+func Replay(game *chess.Game, edits []Edit) (*Editor, error) {
+	e := NewEditor(game)
+	for i, edit := range edits {
+		if err := e.do(edit); err != nil {
+			return nil, fmt.Errorf("edit %d: %w", i+1, err)
+		}
+	}
+	return e, nil
+}
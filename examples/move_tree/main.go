@@ -0,0 +1,597 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -pgn games.pgn -game 2 -san "1.e4 c5 2.Nf3 (2.c3 d5)"
+//	go run . -pgn games.pgn -path 0,0,1,0 -ply 12
+//	go run . -pgn games.pgn -log edits.jsonl
+//	go run . -merge prep.pgn,games.pgn -max-ply 16 -min-games 2
+//	go run . -pgn repertoire.pgn -graph graph.json
+//	go run . -pgn repertoire.pgn -dot tree.dot -depth 12 -width 3
+//
+// -san or -path place a cursor in the move tree, then -ply moves it along
+// the line it is on. The location is printed as a SAN path, a child index
+// path and a FEN. -log replays an edit log, as written by WriteLog, on the
+// game and prints the result as PGN. -merge prints every game of the files
+// merged into a single tree. -graph writes the tree of the game as a JSON
+// graph with transpositions collapsed, -dot as a Graphviz graph, cut to
+// -depth moves and -width continuations per move. Without flags the demo runs on a built-in
+// game.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file")
+	gameNum := flag.Int("game", 1, "number of the game in the file, starting at 1")
+	sanPath := flag.String("san", "", `SAN path, e.g. "1.e4 c5 2.Nf3 (2.c3 d5)"`)
+	indexPath := flag.String("path", "", "comma separated child indices, e.g. 0,0,1,0")
+	ply := flag.Int("ply", -1, "ply to move to along the current line")
+	logPath := flag.String("log", "", "edit log to replay on the game")
+	mergeFiles := flag.String("merge", "", "comma separated PGN files whose games are merged into one tree")
+	maxPly := flag.Int("max-ply", 0, "with -merge, number of moves kept from each game; 0 keeps all")
+	minGames := flag.Int("min-games", 0, "with -merge, leave out moves played in fewer games")
+	graphPath := flag.String("graph", "", "JSON file to write the transposition graph of the game to")
+	dotPath := flag.String("dot", "", "DOT file to write the move tree of the game to")
+	depth := flag.Int("depth", 0, "with -dot, number of moves drawn; 0 draws all")
+	width := flag.Int("width", 0, "with -dot, continuations drawn per move; 0 draws all")
+	flag.Parse()
+
+	if *mergeFiles != "" {
+		var games []*chess.Game
+		for _, path := range strings.Split(*mergeFiles, ",") {
+			g, err := readGames(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			games = append(games, g...)
+		}
+		merged, err := Merge(games, MergeOptions{MaxPly: *maxPly, MinGames: *minGames})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(merged)
+		return
+	}
+	if *pgnPath == "" {
+		runDemo()
+		return
+	}
+
+	game, err := readGame(*pgnPath, *gameNum)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *graphPath != "" {
+		if err := writeGraph(NewGraph(game), *graphPath); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Graph saved to: %s\n", *graphPath)
+		return
+	}
+	if *dotPath != "" {
+		if err := writeDOT(game, *dotPath, DOTOptions{MaxDepth: *depth, MaxWidth: *width}); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("DOT graph saved to: %s\n", *dotPath)
+		return
+	}
+	if *logPath != "" {
+		if err := replayLog(game, *logPath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	cursor := NewCursor(game)
+	if *sanPath != "" {
+		if err := cursor.ToSAN(*sanPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *indexPath != "" {
+		path, err := parsePath(*indexPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := cursor.ToPath(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *ply >= 0 {
+		if err := cursor.ToPly(*ply); err != nil {
+			log.Fatal(err)
+		}
+	}
+	printCursor(cursor)
+}
+
+// readGame returns the n-th game of the file, counting from 1.
+func readGame(path string, n int) (*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := chess.NewScanner(f)
+	for i := 1; scanner.HasNext(); i++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i, err)
+		}
+		if i == n {
+			return game, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has fewer than %d games", path, n)
+}
+
+// readGames returns all the games of a PGN file.
+func readGames(path string) ([]*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var games []*chess.Game
+	scanner := chess.NewScanner(f)
+	for i := 1; scanner.HasNext(); i++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, fmt.Errorf("%s, game %d: %w", path, i, err)
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+func parsePath(s string) ([]int, error) {
+	var path []int
+	for _, field := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid child index %q", field)
+		}
+		path = append(path, i)
+	}
+	return path, nil
+}
+
+func replayLog(game *chess.Game, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	edits, err := ReadLog(f)
+	if err != nil {
+		return err
+	}
+	editor, err := Replay(game, edits)
+	if err != nil {
+		return err
+	}
+	fmt.Println(editor.Game())
+	return nil
+}
+
+func writeGraph(g *Graph, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := g.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeDOT(game *chess.Game, path string, opts DOTOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteDOT(f, game, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printCursor(c *Cursor) {
+	fmt.Printf("SAN path: %s\n", c)
+	fmt.Printf("Path:     %v (ply %d)\n", c.Path(), c.Ply())
+	fmt.Printf("FEN:      %s\n", c.Position())
+}
+
+func runDemo() {
+	fmt.Println("=== Move Tree Example ===")
+
+	pgn, err := chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	game := chess.NewGame(pgn)
+
+	// Example 1: Moving along the main line by ply
+	fmt.Println("\n1. Cursor by Ply")
+	cursor := NewCursor(game)
+	if err := cursor.ToPly(6); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	printCursor(cursor)
+
+	// Example 2: Following child indices into a variation
+	fmt.Println("\n2. Cursor by Child Index Path")
+	if err := cursor.ToPath([]int{0, 0, 1, 0}); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	printCursor(cursor)
+
+	// Example 3: A SAN path, then a ply further down that variation
+	fmt.Println("\n3. Cursor by SAN Path")
+	if err := cursor.ToSAN("1.e4 c5 2.Nf3 d6 3.d4 cxd4 4.Nxd4 Nf6 5.Nc3 a6 (5...g6)"); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	printCursor(cursor)
+	fmt.Println("Moving to ply 12 of this variation:")
+	if err := cursor.ToPly(12); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	printCursor(cursor)
+	if err := cursor.ToSAN("1.e4 c5 2.Bc4"); err != nil {
+		fmt.Printf("A move that is not in the tree: %v\n", err)
+	}
+	fmt.Println("Movetext pasted from a PGN file, with comments and the result:")
+	if err := cursor.ToSAN("1. e4 {Best by test} c5 2. Nf3 $1 d6 ; main line\n3. d4 *"); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	printCursor(cursor)
+
+	// Example 4: Every move of the tree survives a round trip through its
+	// SAN path
+	fmt.Println("\n4. Round Trip Through SAN Paths")
+	total, ok := 0, 0
+	var walk func(path []int)
+	walk = func(path []int) {
+		if err := cursor.ToPath(path); err != nil {
+			return
+		}
+		for i, child := range cursor.Move().Children() {
+			total++
+			cursor.ToPath(append(path, i))
+			back := NewCursor(game)
+			if err := back.ToSAN(cursor.String()); err == nil && back.Move() == child {
+				ok++
+			}
+			walk(append(path, i))
+		}
+	}
+	walk(nil)
+	fmt.Printf("%d of %d moves found again from their SAN path\n", ok, total)
+
+	// Example 5: Handing the location over to the game
+	fmt.Println("\n5. Applying the Cursor to the Game")
+	if err := cursor.ToPath([]int{0, 0, 2, 0}); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	if err := cursor.Apply(); err != nil {
+		log.Printf("Error applying the cursor: %v\n", err)
+		return
+	}
+	fmt.Printf("Cursor at %s\n", cursor)
+	fmt.Printf("Game position: %s\n", game.Position())
+	if err := game.PushMove("g3", nil); err != nil {
+		log.Printf("Error making move: %v\n", err)
+		return
+	}
+	cursor.Forward()
+	fmt.Printf("After PushMove(\"g3\") the cursor moves on to %s\n", cursor)
+
+	// Example 6: Editing with undo and redo
+	fmt.Println("\n6. Undo and Redo")
+	pgn, err = chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	editor := NewEditor(chess.NewGame(pgn))
+	steps := []func() error{
+		func() error { return editor.Cursor().ToSAN("1.e4 c5") },
+		func() error { return editor.PushMove("c3", &chess.PushMoveOptions{ForceMainline: true}) },
+		func() error { return editor.SetComment("The Alapin is our main line now.") },
+		func() error { return editor.PushMove("Nf6", nil) },
+		func() error { return editor.SetNAG("$5") },
+		func() error { return editor.AddVariation("e5") },
+		func() error { return editor.Resign(chess.Black) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			log.Printf("Error editing the game: %v\n", err)
+			return
+		}
+	}
+	for _, edit := range editor.Log() {
+		fmt.Printf("  %s\n", edit)
+	}
+	fmt.Printf("Edited:  %s\n", movetext(editor.Game()))
+	for range 2 {
+		if _, err := editor.Undo(); err != nil {
+			log.Printf("Error undoing: %v\n", err)
+			return
+		}
+	}
+	fmt.Printf("Undo x2: %s\n", movetext(editor.Game()))
+	// The PGN output leaves NAGs out, so the one set on 2...Nf6 is shown
+	// from the move itself.
+	fmt.Printf("Cursor back at %s, NAG %s\n", editor.Cursor(), editor.Cursor().Move().NAG())
+	if _, err := editor.Redo(); err != nil {
+		log.Printf("Error redoing: %v\n", err)
+		return
+	}
+	fmt.Printf("Redo:    %s\n", movetext(editor.Game()))
+
+	// Example 7: Saving the edit log and replaying it on the original game
+	fmt.Println("\n7. Replaying an Edit Log")
+	var saved strings.Builder
+	if err := WriteLog(&saved, editor.Log()); err != nil {
+		log.Printf("Error writing log: %v\n", err)
+		return
+	}
+	fmt.Print(saved.String())
+	edits, err := ReadLog(strings.NewReader(saved.String()))
+	if err != nil {
+		log.Printf("Error reading log: %v\n", err)
+		return
+	}
+	pgn, err = chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	replayed, err := Replay(chess.NewGame(pgn), edits)
+	if err != nil {
+		log.Printf("Error replaying log: %v\n", err)
+		return
+	}
+	fmt.Printf("Replayed game matches: %t\n", replayed.Game().String() == editor.Game().String())
+
+	// Example 8: Merging games into one tree with statistics
+	fmt.Println("\n8. Merging Games")
+	var games []*chess.Game
+	scanner := chess.NewScanner(strings.NewReader(mergePGN))
+	for scanner.HasNext() {
+		g, err := scanner.ParseNext()
+		if err != nil {
+			log.Printf("Error parsing PGN: %v\n", err)
+			return
+		}
+		games = append(games, g)
+	}
+	merged, err := Merge(games, MergeOptions{MaxPly: 6})
+	if err != nil {
+		log.Printf("Error merging games: %v\n", err)
+		return
+	}
+	fmt.Printf("%s\n%s\n", merged.GetTagPair("Event"), movetext(merged))
+	fmt.Println("Moves played in at least two games:")
+	merged, err = Merge(games, MergeOptions{MaxPly: 6, MinGames: 2})
+	if err != nil {
+		log.Printf("Error merging games: %v\n", err)
+		return
+	}
+	fmt.Println(movetext(merged))
+
+	// Example 9: Transpositions, with the statistics of each position
+	// summed over the move orders reaching it
+	fmt.Println("\n9. Transposition Graph")
+	games = nil
+	scanner = chess.NewScanner(strings.NewReader(transpositionPGN))
+	for scanner.HasNext() {
+		g, err := scanner.ParseNext()
+		if err != nil {
+			log.Printf("Error parsing PGN: %v\n", err)
+			return
+		}
+		games = append(games, g)
+	}
+	merged, err = Merge(games, MergeOptions{})
+	if err != nil {
+		log.Printf("Error merging games: %v\n", err)
+		return
+	}
+	graph := NewGraph(merged)
+	moves, edges := -1, 0 // the root move is not a move
+	for _, n := range graph.Nodes {
+		moves += len(n.Moves)
+		edges += len(n.Out)
+	}
+	fmt.Printf("The tree has %d moves, the graph %d positions and %d edges\n", moves, len(graph.Nodes), edges)
+	for _, n := range graph.Transpositions() {
+		var total Stats
+		fmt.Printf("Ply %d, %s:\n", n.Ply, n.Position)
+		for _, m := range n.Moves {
+			stats, _ := ParseStats(m.Comments())
+			total.Games += stats.Games
+			total.WhiteWins += stats.WhiteWins
+			total.Draws += stats.Draws
+			total.BlackWins += stats.BlackWins
+			fmt.Printf("  %-28s %s\n", graph.Path(m), stats)
+		}
+		fmt.Printf("  %-28s %s\n", "all move orders", total)
+	}
+	path := filepath.Join(".", "transpositions.json")
+	if err := writeGraph(graph, path); err != nil {
+		log.Printf("Error writing graph: %v\n", err)
+		return
+	}
+	fmt.Printf("Graph saved to: %s\n", path)
+
+	// Example 10: Graphviz export of the edited game from example 6
+	fmt.Println("\n10. Graphviz DOT Export")
+	path = filepath.Join(".", "move_tree.dot")
+	if err := writeDOT(editor.Game(), path, DOTOptions{}); err != nil {
+		log.Printf("Error writing DOT: %v\n", err)
+		return
+	}
+	fmt.Printf("Full tree saved to: %s\n", path)
+	fmt.Println("The first four moves, two continuations each:")
+	if err := WriteDOT(os.Stdout, editor.Game(), DOTOptions{MaxDepth: 4, MaxWidth: 2, CommentLength: 20}); err != nil {
+		log.Printf("Error writing DOT: %v\n", err)
+	}
+
+	// Example 11: Reordering and removing variations, with undo
+	fmt.Println("\n11. Variation Editing")
+	pgn, err = chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	editor = NewEditor(chess.NewGame(pgn))
+	original := editor.Game().String()
+	type treeEdit struct {
+		at     string
+		label  string
+		action func() error
+	}
+	script := []treeEdit{
+		{"1.e4 c5 2.Nf3 d6 3.d4 cxd4 4.Nxd4 Nf6 5.Nc3 a6 (5...g6)", "Promote 5...g6", editor.Promote},
+		{"1.e4 c5 2.Nf3 d6 3.d4 cxd4 4.Nxd4 Nf6 5.Nc3 g6", "Demote 5...g6", editor.Demote},
+		{"1.e4 c5 2.Nf3 (2.c3 d5 3.exd5)", "Promote 2.c3 line to main line", editor.PromoteToMainLine},
+		{"1.e4 c5 2.c3 (2.Nf3 d6 3.d4)", "Delete 3.d4", editor.Delete},
+		{"1.e4 c5 2.c3 d5", "Truncate after 2...d5", editor.Truncate},
+		{"1.e4 c5 2.c3 (2.Nc3 Nc6)", "Strip variations", editor.StripVariations},
+		{"1.e4", "Delete 1.e4", editor.Delete},
+	}
+	for _, e := range script {
+		if err := editor.Cursor().ToSAN(e.at); err != nil {
+			log.Printf("Error moving the cursor: %v\n", err)
+			return
+		}
+		if err := e.action(); err != nil {
+			log.Printf("Error editing the game: %v\n", err)
+			return
+		}
+		game := editor.Game()
+		fmt.Printf("%s:\n  %s\n", e.label, movetext(game))
+		fmt.Printf("  cursor at %q, game position matches: %t\n",
+			editor.Cursor().String(), game.Position().String() == editor.Cursor().Position().String())
+	}
+	for {
+		undone, err := editor.Undo()
+		if err != nil {
+			log.Printf("Error undoing: %v\n", err)
+			return
+		}
+		if !undone {
+			break
+		}
+	}
+	fmt.Printf("After undoing all edits the game is the original: %t\n", editor.Game().String() == original)
+
+	// Deleting a checkmate takes the result back with it, and undoing the
+	// deletion brings both back
+	pgn, err = chess.PGN(strings.NewReader(matePGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	editor = NewEditor(chess.NewGame(pgn))
+	game = editor.Game()
+	original = game.String()
+	variation := game.Moves()[5].Parent().Children()[1]
+	fmt.Printf("\nBefore:  %s (%s, %s)\n", movetext(game), game.Outcome(), game.Method())
+	if err := editor.Cursor().ToSAN("1.e4 e5 2.Bc4 Nc6 3.Qh5 Nf6 4.Qxf7#"); err != nil {
+		log.Printf("Error moving the cursor: %v\n", err)
+		return
+	}
+	if err := editor.Delete(); err != nil {
+		log.Printf("Error editing the game: %v\n", err)
+		return
+	}
+	fmt.Printf("Deleted: %s (%s, %s)\n", movetext(game), game.Outcome(), game.Method())
+	if _, err := editor.Undo(); err != nil {
+		log.Printf("Error undoing: %v\n", err)
+		return
+	}
+	fmt.Printf("Undone:  %s (%s, %s)\n", movetext(game), game.Outcome(), game.Method())
+	fmt.Printf("Same game and moves as before: %t, 3...g6 still in the tree: %t\n",
+		editor.Game() == game && game.String() == original, indexOf(variation.Parent().Children(), variation) >= 0)
+}
+
+// movetext returns the moves of game as PGN, without the tag pairs.
+func movetext(game *chess.Game) string {
+	s := game.String()
+	if i := strings.LastIndex(s, "]\n"); i >= 0 {
+		s = s[i+2:]
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+const demoPGN = `[Event "Sicilian repertoire"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+
+1. e4 c5 2. Nf3 (2. c3 d5 3. exd5 Qxd5) (2. Nc3 Nc6) 2... d6 3. d4 cxd4
+4. Nxd4 Nf6 5. Nc3 a6 (5... g6 6. Be3 Bg7 7. f3 O-O) 6. Be3 e5 7. Nb3 Be6 *`
+
+const mergePGN = `[Event "Game 1"]
+[Result "1-0"]
+
+1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 6. Be3 e5 1-0
+
+[Event "Game 2"]
+[Result "1/2-1/2"]
+
+1. e4 c5 2. Nf3 Nc6 3. d4 cxd4 4. Nxd4 g6 1/2-1/2
+
+[Event "Game 3"]
+[Result "0-1"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 0-1
+
+[Event "Game 4"]
+[Result "1-0"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 1-0
+
+[Event "Game 5"]
+[Result "0-1"]
+
+1. e4 c5 2. c3 d5 3. exd5 Qxd5 4. d4 Nf6 0-1`
+
+const transpositionPGN = `[Event "Game 1"]
+[Result "1-0"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 4. Qc2 O-O 1-0
+
+[Event "Game 2"]
+[Result "1/2-1/2"]
+
+1. c4 e6 2. d4 Nf6 3. Nf3 d5 1/2-1/2
+
+[Event "Game 3"]
+[Result "0-1"]
+
+1. d4 e6 2. c4 Nf6 3. Nc3 Bb4 4. e3 O-O 0-1`
+
+const matePGN = `[Event "Scholar's mate"]
+[Result "1-0"]
+
+1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 (3... g6) 4. Qxf7# 1-0`
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// MergeOptions limits the size of a merged tree.
+type MergeOptions struct {
+	// MaxPly stops each line after this many moves; 0 keeps whole games.
+	MaxPly int
+	// MinGames drops moves played in fewer games; 0 and 1 keep them all.
+	MinGames int
+}
+
+// Stats counts the games that went through a move of a merged tree.
+type Stats struct {
+	Games     int
+	WhiteWins int
+	Draws     int
+	BlackWins int
+}
+
+func (s Stats) String() string {
+	games := "games"
+	if s.Games == 1 {
+		games = "game"
+	}
+	return fmt.Sprintf("%d %s, +%d =%d -%d", s.Games, games, s.WhiteWins, s.Draws, s.BlackWins)
+}
+
+// ParseStats reads back the Stats that Merge writes as a comment.
+func ParseStats(comment string) (Stats, bool) {
+	var s Stats
+	var games string
+	_, err := fmt.Sscanf(comment, "%d %s +%d =%d -%d", &s.Games, &games, &s.WhiteWins, &s.Draws, &s.BlackWins)
+	return s, err == nil
+}
+
+func (s *Stats) add(outcome chess.Outcome) {
+	s.Games++
+	switch outcome {
+	case chess.WhiteWon:
+		s.WhiteWins++
+	case chess.Draw:
+		s.Draws++
+	case chess.BlackWon:
+		s.BlackWins++
+	}
+}
+
+// mergeNode is a move of the merged tree while it is being built.
+type mergeNode struct {
+	san      string
+	stats    Stats
+	children []*mergeNode
+	// game is the number of the last game counted, so a game whose
+	// variations go through a move more than once counts once.
+	game int
+}
+
+func (n *mergeNode) child(san string) *mergeNode {
+	for _, c := range n.children {
+		if c.san == san {
+			return c
+		}
+	}
+	c := &mergeNode{san: san, game: -1}
+	n.children = append(n.children, c)
+	return c
+}
+
+// Merge combines the move trees of games, variations included, into one
+// game. Lines sharing their first moves share those moves in the tree,
+// the continuations of a move are ordered by the number of games playing
+// them, and each move gets a comment with its Stats (results from White's
+// point of view), replacing the comments of the games. All games must
+// start from the same position.
+func Merge(games []*chess.Game, opts MergeOptions) (*chess.Game, error) {
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no games to merge")
+	}
+	start := games[0].GetRootMove().Position()
+	root := &mergeNode{game: -1}
+	for i, game := range games {
+		if pos := game.GetRootMove().Position(); !samePosition(pos, start) {
+			return nil, fmt.Errorf("game %d starts from %s, not %s", i+1, pos, start)
+		}
+		outcome := result(game)
+		root.stats.add(outcome)
+		var walk func(m *chess.Move, n *mergeNode, ply int)
+		walk = func(m *chess.Move, n *mergeNode, ply int) {
+			if opts.MaxPly > 0 && ply >= opts.MaxPly {
+				return
+			}
+			for _, child := range m.Children() {
+				c := n.child(chess.AlgebraicNotation{}.Encode(m.Position(), child))
+				if c.game != i {
+					c.game = i
+					c.stats.add(outcome)
+				}
+				walk(child, c, ply+1)
+			}
+		}
+		walk(game.GetRootMove(), root, 0)
+	}
+
+	fen, err := chess.FEN(start.String())
+	if err != nil {
+		return nil, err
+	}
+	merged := chess.NewGame(fen)
+	merged.AddTagPair("Event", "Merge of "+root.stats.String())
+	var build func(n *mergeNode, parent *chess.Move) error
+	build = func(n *mergeNode, parent *chess.Move) error {
+		// Most played first; a stable sort keeps the order in which moves
+		// were first seen among equals.
+		sort.SliceStable(n.children, func(i, j int) bool {
+			return n.children[i].stats.Games > n.children[j].stats.Games
+		})
+		for _, c := range n.children {
+			if c.stats.Games < opts.MinGames {
+				continue
+			}
+			if err := merged.PushMove(c.san, nil); err != nil {
+				return err
+			}
+			m, err := findChild(parent, c.san)
+			if err != nil {
+				return err
+			}
+			m.SetComment(c.stats.String())
+			if err := build(c, m); err != nil {
+				return err
+			}
+			merged.GoBack()
+		}
+		return nil
+	}
+	if err := build(root, merged.GetRootMove()); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// result returns the result of game. The PGN parser leaves drawn games
+// without an outcome, so the Result tag is read first.
+func result(game *chess.Game) chess.Outcome {
+	if tag := game.GetTagPair("Result"); tag != "" {
+		return chess.Outcome(tag)
+	}
+	return game.Outcome()
+}
+
+// samePosition compares the placement, side to move, castling rights and
+// en passant square of two positions, ignoring the move counters.
+func samePosition(a, b *chess.Position) bool {
+	fa, fb := strings.Fields(a.String()), strings.Fields(b.String())
+	return len(fa) >= 4 && len(fb) >= 4 && strings.Join(fa[:4], " ") == strings.Join(fb[:4], " ")
+}
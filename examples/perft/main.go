@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -fen "<FEN>" -depth 5            # perft with divide output
+//	go run . -suite                           # check every suite position
+//	go run . -suite -max-nodes 0              # ... at every listed depth
+//	go run . -bench -min-nps 500000           # fail below a nodes/second floor
+//
+// Without flags the demo runs small perfts, the suite up to 200k nodes per
+// check and a short benchmark. -suite and -bench exit with status 1 on a
+// mismatch or when too slow. The same checks run as Go tests:
+//
+//	go test -short .                          # suite counts up to 200k nodes
+//	go test -bench Perft .                    # speed, reported in nodes/s
+func main() {
+	fen := flag.String("fen", "", "position to run perft on (default: starting position)")
+	depth := flag.Int("depth", 0, "perft depth; prints the divide of the root moves")
+	suite := flag.Bool("suite", false, "check the node counts of the perft suite")
+	maxNodes := flag.Uint64("max-nodes", 5_000_000, "skip suite checks expecting more nodes (0 for no limit)")
+	bench := flag.Bool("bench", false, "measure move generation speed")
+	minNPS := flag.Float64("min-nps", 0, "with -bench, fail when slower than this many nodes/second")
+	workers := flag.Int("workers", runtime.NumCPU(), "goroutines sharing out the root moves")
+	flag.Parse()
+
+	switch {
+	case *depth > 0:
+		pos := chess.StartingPosition()
+		if *fen != "" {
+			p, err := parseFEN(*fen)
+			if err != nil {
+				log.Fatal(err)
+			}
+			pos = p
+		}
+		start := time.Now()
+		entries := Divide(pos, *depth, *workers)
+		elapsed := time.Since(start)
+		for _, e := range entries {
+			fmt.Printf("%s: %d\n", e.Move, e.Nodes)
+		}
+		total := Total(entries)
+		fmt.Printf("\nMoves: %d\nNodes: %d\nTime: %v (%.0f nodes/s)\n",
+			len(entries), total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	case *suite:
+		if !runSuite(*maxNodes, *workers) {
+			os.Exit(1)
+		}
+	case *bench:
+		if nps := runBench(*workers); nps < *minNPS {
+			fmt.Printf("FAIL: %.0f nodes/s is below the %.0f floor\n", nps, *minNPS)
+			os.Exit(1)
+		}
+	default:
+		runDemo(*workers)
+	}
+}
+
+// runSuite checks every listed node count of the suite up to maxNodes and
+// reports whether all of them matched.
+func runSuite(maxNodes uint64, workers int) bool {
+	passed, failed, skipped := 0, 0, 0
+	for _, sp := range Suite {
+		pos, err := parseFEN(sp.FEN)
+		if err != nil {
+			fmt.Printf("FAIL %-45s %v\n", sp.Name, err)
+			failed++
+			continue
+		}
+		for i, want := range sp.Nodes {
+			if want == 0 {
+				continue
+			}
+			if maxNodes > 0 && want > maxNodes {
+				skipped++
+				continue
+			}
+			start := time.Now()
+			got := Total(Divide(pos, i+1, workers))
+			status := "ok  "
+			if got != want {
+				status = "FAIL"
+				failed++
+			} else {
+				passed++
+			}
+			fmt.Printf("%s %-45s depth %d: %9d (want %9d) %v\n",
+				status, sp.Name, i+1, got, want, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	fmt.Printf("%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	return failed == 0
+}
+
+// runBench runs perft on three suite positions and returns the combined
+// speed in nodes per second.
+func runBench(workers int) float64 {
+	var nodes uint64
+	var elapsed time.Duration
+	for _, b := range []struct {
+		fen   string
+		depth int
+	}{
+		{Suite[0].FEN, 4},
+		{Suite[1].FEN, 3},
+		{Suite[2].FEN, 5},
+	} {
+		pos, err := parseFEN(b.fen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		start := time.Now()
+		n := Total(Divide(pos, b.depth, workers))
+		d := time.Since(start)
+		fmt.Printf("  depth %d %-70s %9d nodes %8v %10.0f nodes/s\n", b.depth, b.fen, n, d.Round(time.Millisecond), float64(n)/d.Seconds())
+		nodes += n
+		elapsed += d
+	}
+	nps := float64(nodes) / elapsed.Seconds()
+	fmt.Printf("Total: %d nodes in %v, %.0f nodes/s with %d workers\n", nodes, elapsed.Round(time.Millisecond), nps, workers)
+	return nps
+}
+
+func runDemo(workers int) {
+	fmt.Println("=== Perft Example ===")
+
+	// Example 1: Perft from the starting position
+	fmt.Println("\n1. Perft From the Starting Position")
+	for depth := 1; depth <= 3; depth++ {
+		fmt.Printf("  depth %d: %d nodes\n", depth, Perft(chess.StartingPosition(), depth))
+	}
+
+	// Example 2: Divide, for tracking down a wrong count
+	fmt.Println("\n2. Divide of Kiwipete at Depth 2 (first 8 moves)")
+	kiwipete, err := parseFEN(Suite[1].FEN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	entries := Divide(kiwipete, 2, workers)
+	for _, e := range entries[:8] {
+		fmt.Printf("  %s: %d\n", e.Move, e.Nodes)
+	}
+	fmt.Printf("  ... %d moves, %d nodes\n", len(entries), Total(entries))
+
+	// Example 3: The correctness suite
+	fmt.Println("\n3. Perft Suite (checks up to 200k nodes)")
+	runSuite(200_000, workers)
+
+	// Example 4: Speed
+	fmt.Println("\n4. Benchmark")
+	runBench(workers)
+}
+
+func parseFEN(fen string) (*chess.Position, error) {
+	opt, err := chess.FEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(opt).Position(), nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Perft counts the leaf nodes of the legal move tree of pos to the given
+// depth. Comparing the counts against known values is the standard way of
+// checking a move generator: a single missing or extra move anywhere in
+// the tree changes the total.
+func Perft(pos *chess.Position, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := pos.ValidMoves()
+	if depth == 1 {
+		// Bulk counting: the leaves are the legal moves themselves.
+		return uint64(len(moves))
+	}
+	var nodes uint64
+	for i := range moves {
+		nodes += Perft(pos.Update(&moves[i]), depth-1)
+	}
+	return nodes
+}
+
+// DivideEntry is the node count below one root move.
+type DivideEntry struct {
+	Move  string // in UCI notation
+	Nodes uint64
+}
+
+// Divide runs perft to depth-1 below every legal move of pos and returns
+// the counts sorted by move. When a total is wrong, comparing the divide
+// output with a trusted engine's points to the faulty subtree; repeating
+// that from the faulty move's position narrows it down to the bad move.
+//
+// The root moves are shared out between workers goroutines.
+func Divide(pos *chess.Position, depth, workers int) []DivideEntry {
+	if depth < 1 {
+		return nil
+	}
+	moves := pos.ValidMoves()
+	entries := make([]DivideEntry, len(moves))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				entries[i] = DivideEntry{
+					Move:  chess.UCINotation{}.Encode(pos, &moves[i]),
+					Nodes: Perft(pos.Update(&moves[i]), depth-1),
+				}
+			}
+		}()
+	}
+	for i := range moves {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Move < entries[j].Move })
+	return entries
+}
+
+// Total adds up the node counts of a divide.
+func Total(entries []DivideEntry) uint64 {
+	var total uint64
+	for _, e := range entries {
+		total += e.Nodes
+	}
+	return total
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// shortMaxNodes is the largest count checked with -short.
+const shortMaxNodes = 200_000
+
+func TestPerft(t *testing.T) {
+	for _, sp := range Suite {
+		pos, err := parseFEN(sp.FEN)
+		if err != nil {
+			t.Fatalf("%s: %v", sp.Name, err)
+		}
+		for i, want := range sp.Nodes {
+			if want == 0 {
+				continue
+			}
+			depth := i + 1
+			t.Run(fmt.Sprintf("%s/depth=%d", sp.Name, depth), func(t *testing.T) {
+				if testing.Short() && want > shortMaxNodes {
+					t.Skipf("%d nodes, skipped in short mode", want)
+				}
+				t.Parallel()
+				if got := Perft(pos, depth); got != want {
+					t.Errorf("perft(%d) = %d, want %d", depth, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestDivideMatchesPerft(t *testing.T) {
+	pos, err := parseFEN(Suite[1].FEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := Divide(pos, 3, runtime.NumCPU())
+	if len(entries) != 48 {
+		t.Errorf("divide has %d moves, want 48", len(entries))
+	}
+	if got, want := Total(entries), Perft(pos, 3); got != want {
+		t.Errorf("divide total = %d, perft = %d", got, want)
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	for _, bc := range []struct {
+		name  string
+		fen   string
+		depth int
+	}{
+		{"start", Suite[0].FEN, 4},
+		{"kiwipete", Suite[1].FEN, 3},
+		{"position3", Suite[2].FEN, 5},
+	} {
+		pos, err := parseFEN(bc.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bc.name, func(b *testing.B) {
+			var nodes uint64
+			for range b.N {
+				nodes += Perft(pos, bc.depth)
+			}
+			b.ReportMetric(float64(nodes)/b.Elapsed().Seconds(), "nodes/s")
+		})
+	}
+}
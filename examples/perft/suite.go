@@ -0,0 +1,115 @@
+package main
+
+// SuitePosition is a position with its known perft node counts; Nodes[i]
+// is the count at depth i+1, or 0 where the count is not listed.
+type SuitePosition struct {
+	Name  string
+	FEN   string
+	Nodes []uint64
+}
+
+// Suite holds the standard perft positions from the Chess Programming
+// Wiki together with the well known set of edge cases covering en passant
+// pins, castling through and out of check, promotions and stalemate.
+var Suite = []SuitePosition{
+	{
+		Name:  "Starting position",
+		FEN:   "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		Nodes: []uint64{20, 400, 8902, 197281, 4865609},
+	},
+	{
+		Name:  "Kiwipete",
+		FEN:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		Nodes: []uint64{48, 2039, 97862, 4085603},
+	},
+	{
+		Name:  "Position 3 (en passant and rook endgame)",
+		FEN:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		Nodes: []uint64{14, 191, 2812, 43238, 674624},
+	},
+	{
+		Name:  "Position 4 (promotions and castling)",
+		FEN:   "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		Nodes: []uint64{6, 264, 9467, 422333},
+	},
+	{
+		Name:  "Position 5",
+		FEN:   "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		Nodes: []uint64{44, 1486, 62379, 2103487},
+	},
+	{
+		Name:  "Position 6",
+		FEN:   "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		Nodes: []uint64{46, 2079, 89890, 3894594},
+	},
+	{
+		Name:  "Illegal en passant (pinned by a rook)",
+		FEN:   "3k4/3p4/8/K1P4r/8/8/8/8 b - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 1134888},
+	},
+	{
+		Name:  "Illegal en passant (pinned by a bishop)",
+		FEN:   "8/8/4k3/8/2p5/8/B2P2K1/8 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 1015133},
+	},
+	{
+		Name:  "En passant capture checks the opponent",
+		FEN:   "8/8/1k6/2b5/2pP4/8/5K2/8 b - d3 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 1440467},
+	},
+	{
+		Name:  "Short castling gives check",
+		FEN:   "5k2/8/8/8/8/8/8/4K2R w K - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 661072},
+	},
+	{
+		Name:  "Long castling gives check",
+		FEN:   "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 803711},
+	},
+	{
+		Name:  "Castling rights lost by captures",
+		FEN:   "r3k2r/1b4bq/8/8/8/8/7B/R3K2R w KQkq - 0 1",
+		Nodes: []uint64{0, 0, 0, 1274206},
+	},
+	{
+		Name:  "Castling prevented by attacks",
+		FEN:   "r3k2r/8/3Q4/8/8/5q2/8/R3K2R b KQkq - 0 1",
+		Nodes: []uint64{0, 0, 0, 1720476},
+	},
+	{
+		Name:  "Promotion out of check",
+		FEN:   "2K2r2/4P3/8/8/8/8/8/3k4 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 3821001},
+	},
+	{
+		Name:  "Discovered check",
+		FEN:   "8/8/1P2K3/8/2n5/1q6/8/5k2 b - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 1004658},
+	},
+	{
+		Name:  "Promotion gives check",
+		FEN:   "4k3/1P6/8/8/8/8/K7/8 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 217342},
+	},
+	{
+		Name:  "Underpromotion gives check",
+		FEN:   "8/P1k5/K7/8/8/8/8/8 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 92683},
+	},
+	{
+		Name:  "Self stalemate",
+		FEN:   "K1k5/8/P7/8/8/8/8/8 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 2217},
+	},
+	{
+		Name:  "Stalemate and checkmate (pawn)",
+		FEN:   "8/k1P5/8/1K6/8/8/8/8 w - - 0 1",
+		Nodes: []uint64{0, 0, 0, 0, 0, 0, 567584},
+	},
+	{
+		Name:  "Stalemate and checkmate (queen and knight)",
+		FEN:   "8/8/2k5/5q2/5n2/8/5K2/8 b - - 0 1",
+		Nodes: []uint64{0, 0, 0, 23527},
+	},
+}
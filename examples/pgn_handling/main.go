@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -34,32 +36,27 @@ func main() {
 		}
 	}
 
-	// Example 2: Reading multiple games from a single file
+	// Example 2: Streaming multiple games from a single file
 	fmt.Println("\n2. Reading Multiple Games")
 	multiPath := filepath.Join("..", "..", "fixtures", "pgns", "multi_game.pgn")
-	multiData, err := os.ReadFile(multiPath)
+	multiFile, err := os.Open(multiPath)
 	if err != nil {
-		log.Printf("Error reading multi-game PGN: %v\n", err)
+		log.Printf("Error opening multi-game PGN: %v\n", err)
 	} else {
-		games := strings.Split(string(multiData), "\n\n\n")
-		fmt.Printf("Found %d games in file\n", len(games))
-		
-		// Parse first game as example
-		if len(games) > 0 {
-			pgnGame, err := chess.PGN(strings.NewReader(games[0]))
-			if err != nil {
-				log.Printf("Error parsing first game: %v\n", err)
-			} else {
-				game := chess.NewGame(pgnGame)
-				fmt.Printf("First game details:\n")
-				fmt.Printf("Event: %s\n", game.GetTagPair("Event"))
-				fmt.Printf("White: %s\n", game.GetTagPair("White"))
-				fmt.Printf("Black: %s\n", game.GetTagPair("Black"))
-				fmt.Printf("Result: %s\n", game.GetTagPair("Result"))
-			}
-		}
+		count, failed := readAllGames(multiFile)
+		multiFile.Close()
+		fmt.Printf("Found %d games in file (%d could not be parsed)\n", count, failed)
 	}
 
+	// Example 2b: Streaming a messy multi-game source
+	// CRLF line endings, single blank lines between games, blank lines
+	// inside comments and a broken game in the middle are all handled;
+	// the broken game is reported with its location and skipped.
+	fmt.Println("\n2b. Streaming a Messy Multi-Game Source")
+	messy := strings.ReplaceAll(messyPGN, "\n", "\r\n")
+	count, failed := readAllGames(strings.NewReader(messy))
+	fmt.Printf("Read %d games (%d could not be parsed)\n", count, failed)
+
 	// Example 3: Reading a complete game with rich metadata
 	fmt.Println("\n3. Reading Complete Game with Metadata")
 	completePath := filepath.Join("..", "..", "fixtures", "pgns", "complete_game.pgn")
@@ -85,7 +82,7 @@ func main() {
 	// Example 4: Creating and exporting PGN
 	fmt.Println("\n4. Creating and Exporting PGN")
 	game := chess.NewGame()
-	
+
 	// Add some moves
 	moves := []string{"e4", "e5", "Nf3", "Nc6"}
 	for _, move := range moves {
@@ -103,3 +100,67 @@ func main() {
 	// Export to PGN string
 	fmt.Printf("\nExported PGN:\n%s\n", game.String())
 }
+
+// readAllGames streams every game from r, printing a short summary of each
+// one and reporting broken games without stopping. It returns the number
+// of games read and how many of them failed to parse.
+func readAllGames(r io.Reader) (int, int) {
+	reader := NewGameReader(r)
+	count, failed := 0, 0
+	for {
+		sg, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var gameErr *GameError
+		if errors.As(err, &gameErr) {
+			count++
+			failed++
+			log.Printf("Skipping %v\n", gameErr)
+			continue
+		}
+		if err != nil {
+			log.Printf("Error reading PGN: %v\n", err)
+			break
+		}
+
+		count++
+		game := sg.Game
+		fmt.Printf("Game %d (line %d): %s vs %s, %s, %d plies\n",
+			sg.Index+1, sg.Line,
+			game.GetTagPair("White"), game.GetTagPair("Black"),
+			game.GetTagPair("Result"), len(game.Moves()))
+	}
+	return count, failed
+}
+
+const messyPGN = `[Event "First"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0
+
+[Event "Second"]
+[White "Carol"]
+[Black "Dave"]
+[Result "1/2-1/2"]
+
+1. d4 d5 {A comment
+
+that spans a blank line} 2. c4 e6 1/2-1/2
+
+[Event "Broken"]
+[White "Eve"]
+[Black "Mallory"]
+[Result "*"]
+
+1. e4 e5 2. Ke3 Ke6 *
+
+[Event "Third"]
+[White "Frank"]
+[Black "Grace"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
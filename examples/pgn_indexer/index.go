@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// indexVersion is bumped whenever the on-disk layout of Index changes so
+// stale index files are rebuilt instead of being misread.
+const indexVersion = 1
+
+// GameEntry is everything the index keeps about a single game. Offset and
+// Length locate the game's text in the source file so it can be printed
+// without reparsing the rest of the file.
+type GameEntry struct {
+	Tags   map[string]string
+	ECO    string
+	Result string
+	Offset int64
+	Length int
+	Line   int
+	Plies  int
+}
+
+// Index is a searchable summary of a PGN file: the tags of every game and
+// the Zobrist hash of every position reached in each game's main line.
+type Index struct {
+	Version    int
+	Source     string // absolute path of the PGN file
+	SourceSize int64
+	SourceMod  time.Time
+	Games      []GameEntry
+	Positions  map[uint64][]int // position hash -> ids of the games reaching it
+	Skipped    int              // games that could not be parsed
+}
+
+// BuildIndex reads every game in the PGN file at path and indexes its tags
+// and positions. Games that fail to parse are logged and skipped. The
+// index stores the absolute path of the file, so Stale and ReadGame still
+// find it when the index is loaded from another directory.
+func BuildIndex(path string) (*Index, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{
+		Version:    indexVersion,
+		Source:     path,
+		SourceSize: info.Size(),
+		SourceMod:  info.ModTime(),
+		Positions:  map[uint64][]int{},
+	}
+
+	hasher := chess.NewZobristHasher()
+	reader := NewGameReader(f)
+	for {
+		sg, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var gameErr *GameError
+		if errors.As(err, &gameErr) {
+			log.Printf("Skipping %v\n", gameErr)
+			idx.Skipped++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := len(idx.Games)
+		idx.Games = append(idx.Games, newGameEntry(sg))
+
+		seen := map[uint64]bool{}
+		for _, pos := range sg.Game.Positions() {
+			hash, err := positionHash(hasher, pos)
+			if err != nil {
+				return nil, fmt.Errorf("game %d: %w", id+1, err)
+			}
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			idx.Positions[hash] = append(idx.Positions[hash], id)
+		}
+	}
+
+	return idx, nil
+}
+
+func newGameEntry(sg *ScannedGame) GameEntry {
+	game := sg.Game
+	tags := map[string]string{}
+	for _, name := range indexedTags {
+		if value := game.GetTagPair(name); value != "" {
+			tags[name] = value
+		}
+	}
+
+	result := game.GetTagPair("Result")
+	if result == "" {
+		result = game.Outcome().String()
+	}
+
+	return GameEntry{
+		Tags:   tags,
+		ECO:    game.GetTagPair("ECO"),
+		Result: result,
+		Offset: sg.Offset,
+		Length: len(sg.Raw),
+		Line:   sg.Line,
+		Plies:  len(game.Moves()),
+	}
+}
+
+// indexedTags lists the tag pairs kept in the index. chess.Game has no way
+// to enumerate its tags, so the index sticks to the usual ones.
+var indexedTags = []string{
+	"Event", "Site", "Date", "Round", "White", "Black", "Result",
+	"WhiteElo", "BlackElo", "ECO", "Opening", "Variation", "TimeControl", "Termination",
+}
+
+// positionHash returns the Polyglot-compatible Zobrist hash of pos.
+func positionHash(hasher *chess.ZobristHasher, pos *chess.Position) (uint64, error) {
+	hash, err := hasher.HashPosition(pos.String())
+	if err != nil {
+		return 0, err
+	}
+	return chess.ZobristHashToUint64(hash), nil
+}
+
+// Save writes the index to path.
+func (idx *Index) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(idx); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadIndex reads an index written by Save.
+func LoadIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil {
+		return nil, fmt.Errorf("reading index %s: %w", path, err)
+	}
+	if idx.Version != indexVersion {
+		return nil, fmt.Errorf("index %s has version %d, want %d", path, idx.Version, indexVersion)
+	}
+	return idx, nil
+}
+
+// Stale reports whether the source file changed since the index was built.
+func (idx *Index) Stale() bool {
+	info, err := os.Stat(idx.Source)
+	if err != nil {
+		return true
+	}
+	return info.Size() != idx.SourceSize || !info.ModTime().Equal(idx.SourceMod)
+}
+
+// GamesReaching returns the ids of the games that reach the position given
+// as a FEN string, in file order.
+func (idx *Index) GamesReaching(fen string) ([]int, error) {
+	hash, err := chess.NewZobristHasher().HashPosition(fen)
+	if err != nil {
+		return nil, err
+	}
+	ids := idx.Positions[chess.ZobristHashToUint64(hash)]
+	return append([]int(nil), ids...), nil
+}
+
+// Filter returns the ids of the games matching q, in file order. If ids is
+// not nil only those games are considered.
+func (idx *Index) Filter(q Query, ids []int) []int {
+	if ids == nil {
+		ids = make([]int, len(idx.Games))
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+
+	var matches []int
+	for _, id := range ids {
+		if q.Match(idx.Games[id]) {
+			matches = append(matches, id)
+		}
+	}
+	sort.Ints(matches)
+	return matches
+}
+
+// ReadGame returns the PGN text of the game with the given id straight from
+// the source file.
+func (idx *Index) ReadGame(id int) (string, error) {
+	f, err := os.Open(idx.Source)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	entry := idx.Games[id]
+	buf := make([]byte, entry.Length)
+	if _, err := f.ReadAt(buf, entry.Offset); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// field returns the value of a named field of the entry. The computed
+// fields Plies, ECO and Result take precedence over tags of the same name.
+func (e GameEntry) field(name string) string {
+	switch name {
+	case "Plies", "PlyCount":
+		return strconv.Itoa(e.Plies)
+	case "ECO":
+		return e.ECO
+	case "Result":
+		return e.Result
+	}
+	return e.Tags[name]
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Usage:
+//
+//	go run . -pgn games.pgn                          # build (or refresh) games.pgn.idx
+//	go run . -pgn games.pgn -fen "<FEN>"             # games reaching a position
+//	go run . -pgn games.pgn -where "White=Carlsen AND Result=1-0"
+//	go run . -pgn games.pgn -where "ECO~B9" -show    # also print the matching games
+//
+// Without flags a small demo database is indexed and queried.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN file to index")
+	indexPath := flag.String("index", "", "index file (default: <pgn>.idx)")
+	fen := flag.String("fen", "", "only list games reaching this position")
+	where := flag.String("where", "", `tag query, e.g. "White=Carlsen AND Result=1-0"`)
+	show := flag.Bool("show", false, "print the PGN of every matching game")
+	rebuild := flag.Bool("rebuild", false, "rebuild the index even if it is up to date")
+	flag.Parse()
+
+	if *pgnPath == "" && *indexPath == "" {
+		runDemo()
+		return
+	}
+
+	if *indexPath == "" {
+		*indexPath = *pgnPath + ".idx"
+	}
+	idx, err := openIndex(*pgnPath, *indexPath, *rebuild)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Index %s: %d games, %d distinct positions\n", *indexPath, len(idx.Games), len(idx.Positions))
+
+	if *fen == "" && *where == "" {
+		return
+	}
+	if err := search(idx, *fen, *where, *show); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// openIndex loads the index at indexPath, building it from pgnPath first if
+// it is missing, stale or a rebuild was requested.
+func openIndex(pgnPath, indexPath string, rebuild bool) (*Index, error) {
+	if !rebuild {
+		idx, err := LoadIndex(indexPath)
+		if err == nil && (pgnPath == "" || !idx.Stale()) {
+			return idx, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Rebuilding index: %v\n", err)
+		}
+	}
+	if pgnPath == "" {
+		return nil, fmt.Errorf("index %s not usable and no -pgn given to rebuild it", indexPath)
+	}
+
+	idx, err := BuildIndex(pgnPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.Save(indexPath); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// search runs a position and/or tag query and prints the matching games.
+func search(idx *Index, fen, where string, show bool) error {
+	q, err := ParseQuery(where)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	if fen != "" {
+		if ids, err = idx.GamesReaching(fen); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			fmt.Println("No games found")
+			return nil
+		}
+	}
+
+	matches := idx.Filter(q, ids)
+	fmt.Printf("%d matching games\n", len(matches))
+	for _, id := range matches {
+		g := idx.Games[id]
+		fmt.Printf("  #%d (line %d) %s - %s  %s  %s  %d plies\n",
+			id+1, g.Line, g.Tags["White"], g.Tags["Black"], g.Result, g.ECO, g.Plies)
+		if show {
+			text, err := idx.ReadGame(id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n%s\n\n", text)
+		}
+	}
+	return nil
+}
+
+func runDemo() {
+	fmt.Println("=== PGN Indexer Example ===")
+
+	dir, err := os.MkdirTemp("", "pgn_indexer")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pgnPath := filepath.Join(dir, "demo.pgn")
+	if err := os.WriteFile(pgnPath, []byte(demoPGN), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 1: Building the index
+	fmt.Println("\n1. Building the Index")
+	idx, err := openIndex(pgnPath, pgnPath+".idx", false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Indexed %d games, %d distinct positions\n", len(idx.Games), len(idx.Positions))
+
+	// Example 2: Tag queries
+	fmt.Println("\n2. Tag Query: White=Carlsen AND Result=1-0")
+	if err := search(idx, "", "White=Carlsen AND Result=1-0", false); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("\n3. Numeric Query: WhiteElo>2800 AND Plies<=20")
+	if err := search(idx, "", "WhiteElo>2800 AND Plies<=20", false); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 4: Position search. Both Queen's Gambit Declined move orders
+	// reach the same position, so both games are found.
+	fen := "rnbqkb1r/ppp2ppp/4pn2/3p4/2PP4/2N5/PP2PPPP/R1BQKBNR w KQkq - 2 4"
+	fmt.Printf("\n4. Position Query:\n%s\n", fen)
+	if err := search(idx, fen, "", false); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 5: Combining both and printing the game from the source file
+	fmt.Println("\n5. Position + Tag Query, Printing Matches")
+	if err := search(idx, fen, "Black~Nepo", true); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 6: Reopening a saved index skips the PGN file entirely
+	fmt.Println("\n6. Reloading the Saved Index")
+	reloaded, err := LoadIndex(pgnPath + ".idx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Loaded %d games (stale: %v)\n", len(reloaded.Games), reloaded.Stale())
+}
+
+const demoPGN = `[Event "Demo Open"]
+[White "Carlsen"]
+[Black "Nepomniachtchi"]
+[Result "1-0"]
+[WhiteElo "2855"]
+[BlackElo "2792"]
+[ECO "D37"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 d5 4. Nf3 Be7 5. Bf4 O-O 1-0
+
+[Event "Demo Open"]
+[White "Caruana"]
+[Black "Carlsen"]
+[Result "1/2-1/2"]
+[WhiteElo "2820"]
+[BlackElo "2855"]
+[ECO "C65"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 Nf6 4. d3 Bc5 1/2-1/2
+
+[Event "Demo Open"]
+[White "Ding"]
+[Black "Nepomniachtchi"]
+[Result "0-1"]
+[WhiteElo "2788"]
+[BlackElo "2792"]
+[ECO "D31"]
+
+1. c4 e6 2. Nc3 d5 3. d4 Nf6 4. cxd5 exd5 0-1
+
+[Event "Demo Open"]
+[White "Carlsen"]
+[Black "Ding"]
+[Result "1-0"]
+[WhiteElo "2855"]
+[BlackElo "2788"]
+[ECO "B90"]
+
+1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 1-0
+`
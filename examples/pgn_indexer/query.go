@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Condition is a single "Field<op>Value" test such as White=Carlsen or
+// Plies>=80.
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Query is a list of conditions that must all hold, written as
+// "White=Carlsen AND Result=1-0".
+type Query []Condition
+
+var (
+	andSeparator = regexp.MustCompile(`(?i)\s+AND\s+`)
+	conditionRe  = regexp.MustCompile(`^\s*([A-Za-z]+)\s*(!=|<=|>=|=|<|>|~)\s*(.*?)\s*$`)
+)
+
+// ParseQuery parses a query string. Field names are matched without regard
+// to case against the indexed tags and the Plies field. Supported operators
+// are = and != (case-insensitive equality), ~ (substring) and <, <=, >, >=
+// (numeric comparison, e.g. WhiteElo>2700).
+func ParseQuery(s string) (Query, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var q Query
+	for _, part := range andSeparator.Split(strings.TrimSpace(s), -1) {
+		m := conditionRe.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("invalid condition %q", part)
+		}
+		field, ok := canonicalField(m[1])
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", m[1])
+		}
+		value := strings.Trim(m[3], `"`)
+		switch m[2] {
+		case "<", "<=", ">", ">=":
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("condition %q needs a number", part)
+			}
+		}
+		q = append(q, Condition{Field: field, Op: m[2], Value: value})
+	}
+	return q, nil
+}
+
+func canonicalField(name string) (string, bool) {
+	for _, field := range append([]string{"Plies", "PlyCount"}, indexedTags...) {
+		if strings.EqualFold(field, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// Match reports whether the game satisfies every condition of the query.
+func (q Query) Match(e GameEntry) bool {
+	for _, c := range q {
+		if !c.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether the game satisfies the condition.
+func (c Condition) Match(e GameEntry) bool {
+	actual := e.field(c.Field)
+	switch c.Op {
+	case "=":
+		return strings.EqualFold(actual, c.Value)
+	case "!=":
+		return !strings.EqualFold(actual, c.Value)
+	case "~":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.Value))
+	}
+
+	a, err := strconv.Atoi(actual)
+	if err != nil {
+		return false
+	}
+	b, _ := strconv.Atoi(c.Value)
+	switch c.Op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func (c Condition) String() string {
+	return c.Field + c.Op + c.Value
+}
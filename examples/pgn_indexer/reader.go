@@ -0,0 +1,199 @@
+package main
+
+// This file is a verbatim copy of ../pgn_handling/reader.go. Every example
+// is its own main package, so the game reader cannot be imported from
+// there; keep the two files identical when changing either.
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+
+	"github.com/corentings/chess/v2"
+)
+
+// GameError describes a game that could not be parsed. Offset and Line
+// point at the first byte of the game's text in the source, so the broken
+// game can be located in the original file.
+type GameError struct {
+	Index  int   // zero based index of the game in the source
+	Offset int64 // byte offset of the game's first character
+	Line   int   // one based line number of the game's first character
+	Err    error
+}
+
+func (e *GameError) Error() string {
+	return fmt.Sprintf("game %d (offset %d, line %d): %v", e.Index+1, e.Offset, e.Line, e.Err)
+}
+
+func (e *GameError) Unwrap() error {
+	return e.Err
+}
+
+// ScannedGame is a single game read from a PGN source, along with the
+// location of its text in that source.
+type ScannedGame struct {
+	Game   *chess.Game
+	Raw    string
+	Index  int
+	Offset int64
+	Line   int
+}
+
+// GameReader walks a PGN source game by game using chess.NewScanner.
+// Only the game currently being read (plus the scanner's read-ahead) is
+// kept in memory, so arbitrarily large files can be processed.
+//
+// Example:
+//
+//	reader := NewGameReader(f)
+//	for {
+//		sg, err := reader.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		var gameErr *GameError
+//		if errors.As(err, &gameErr) {
+//			log.Println(gameErr) // skip the broken game and keep going
+//			continue
+//		}
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		// Process sg.Game
+//	}
+type GameReader struct {
+	scanner *chess.Scanner
+	tracker *offsetTracker
+	index   int
+}
+
+// NewGameReader creates a GameReader reading from r.
+func NewGameReader(r io.Reader) *GameReader {
+	tracker := &offsetTracker{r: r, line: 1}
+	return &GameReader{
+		scanner: chess.NewScanner(tracker),
+		tracker: tracker,
+	}
+}
+
+// Next returns the next game in the source. It returns io.EOF once the
+// source is exhausted. A game that fails to parse is reported as a
+// *GameError; the reader has already moved past it, so calling Next again
+// continues with the following game. Any other error is fatal: it comes
+// from the underlying reader, or is bufio.ErrTooLong for a game of more
+// than bufio.MaxScanTokenSize (64 KiB) of text, which chess.Scanner cannot
+// read past.
+func (gr *GameReader) Next() (*ScannedGame, error) {
+	scanned, err := gr.scanner.ScanGame()
+	if errors.Is(err, bufio.ErrTooLong) {
+		offset, line := gr.tracker.next()
+		return nil, fmt.Errorf("game %d (offset %d, line %d) is larger than %d bytes: %w",
+			gr.index+1, offset, line, bufio.MaxScanTokenSize, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := gr.index
+	gr.index++
+	offset, line := gr.tracker.locate(scanned.Raw)
+
+	game, err := parseScannedGame(scanned)
+	if err != nil {
+		return nil, &GameError{Index: index, Offset: offset, Line: line, Err: err}
+	}
+
+	return &ScannedGame{
+		Game:   game,
+		Raw:    scanned.Raw,
+		Index:  index,
+		Offset: offset,
+		Line:   line,
+	}, nil
+}
+
+// parseScannedGame tokenizes and parses a single scanned game, turning
+// parser panics on badly malformed input into errors so one broken game
+// cannot abort the whole file.
+func parseScannedGame(scanned *chess.GameScanned) (game *chess.Game, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			game, err = nil, fmt.Errorf("malformed game: %v", r)
+		}
+	}()
+
+	tokens, err := chess.TokenizeGame(scanned)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty game")
+	}
+	return chess.NewParser(tokens).Parse()
+}
+
+// offsetTracker sits between the PGN source and the scanner and remembers
+// the bytes that have been read but not yet matched to a game. Once the
+// scanner hands back a game, locate finds its text in that window and
+// drops everything before the end of the game, which keeps the window no
+// larger than the scanner's own read-ahead.
+type offsetTracker struct {
+	r       io.Reader
+	pending []byte
+	offset  int64 // source offset of pending[0]
+	line    int   // line number of pending[0]
+}
+
+func (t *offsetTracker) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.pending = append(t.pending, p[:n]...)
+	return n, err
+}
+
+// locate returns the byte offset and line number at which raw starts.
+// The scanner returns each game trimmed but otherwise untouched, so raw
+// is always a contiguous run of bytes from the source.
+func (t *offsetTracker) locate(raw string) (int64, int) {
+	idx := bytes.Index(t.pending, []byte(raw))
+	if idx < 0 {
+		t.trim(bufio.MaxScanTokenSize)
+		return t.offset, t.line
+	}
+
+	offset := t.offset + int64(idx)
+	line := t.line + bytes.Count(t.pending[:idx], []byte{'\n'})
+
+	consumed := idx + len(raw)
+	t.offset += int64(consumed)
+	t.line = line + bytes.Count(t.pending[idx:consumed], []byte{'\n'})
+	t.pending = append(t.pending[:0], t.pending[consumed:]...)
+
+	return offset, line
+}
+
+// next returns the byte offset and line number of the first pending byte
+// that is not white space, where the scanner's next game starts.
+func (t *offsetTracker) next() (int64, int) {
+	idx := bytes.IndexFunc(t.pending, func(r rune) bool { return !unicode.IsSpace(r) })
+	if idx < 0 {
+		idx = len(t.pending)
+	}
+	return t.offset + int64(idx), t.line + bytes.Count(t.pending[:idx], []byte{'\n'})
+}
+
+// trim drops all but the last n pending bytes. The text the scanner has
+// not returned yet fits in its buffer, so when a game cannot be found the
+// bytes before that are of no use and would otherwise pile up.
+func (t *offsetTracker) trim(n int) {
+	if len(t.pending) <= n {
+		return
+	}
+	drop := len(t.pending) - n
+	t.offset += int64(drop)
+	t.line += bytes.Count(t.pending[:drop], []byte{'\n'})
+	t.pending = append(t.pending[:0], t.pending[drop:]...)
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/corentings/chess/v2"
+)
+
+// entrySize is the size of a single Polyglot book entry: a 64 bit key,
+// a 16 bit move, a 16 bit weight and 32 bits of learning data, all big
+// endian.
+const entrySize = 16
+
+// Key returns the Polyglot Zobrist key of a position. The en passant
+// square only contributes when a pawn of the side to move can actually
+// capture on it, as the Polyglot standard requires.
+func Key(pos *chess.Position) (uint64, error) {
+	hash, err := chess.NewZobristHasher().HashPosition(pos.String())
+	if err != nil {
+		return 0, err
+	}
+	return chess.ZobristHashToUint64(hash), nil
+}
+
+// BookMove is a candidate move found in a book, legal in the position it
+// was looked up for.
+type BookMove struct {
+	Move   *chess.Move
+	Weight uint16
+	Learn  uint32
+}
+
+// BookReader looks positions up in a Polyglot .bin file. Entries are
+// sorted by key on disk, so lookups are a binary search over the file and
+// the book is never loaded into memory.
+type BookReader struct {
+	r       io.ReaderAt
+	entries int64
+	closer  io.Closer
+}
+
+// OpenBook opens the Polyglot book at path.
+func OpenBook(path string) (*BookReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	book, err := NewBookReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	book.closer = f
+	return book, nil
+}
+
+// NewBookReader reads a Polyglot book of the given size from r.
+func NewBookReader(r io.ReaderAt, size int64) (*BookReader, error) {
+	if size%entrySize != 0 {
+		return nil, fmt.Errorf("polyglot: book size %d is not a multiple of %d", size, entrySize)
+	}
+	return &BookReader{r: r, entries: size / entrySize}, nil
+}
+
+// Close closes the underlying file when the book was opened with OpenBook.
+func (b *BookReader) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// Len returns the number of entries in the book.
+func (b *BookReader) Len() int64 {
+	return b.entries
+}
+
+// Entries returns the raw book entries stored for key, in file order.
+func (b *BookReader) Entries(key uint64) ([]chess.PolyglotEntry, error) {
+	var readErr error
+	first := sort.Search(int(b.entries), func(i int) bool {
+		e, err := b.entryAt(int64(i))
+		if err != nil {
+			readErr = err
+			return true
+		}
+		return e.Key >= key
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var entries []chess.PolyglotEntry
+	for i := int64(first); i < b.entries; i++ {
+		e, err := b.entryAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if e.Key != key {
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Moves returns the book moves for pos, highest weight first. Entries whose
+// move is not legal in pos (a key collision or a broken book) are dropped.
+func (b *BookReader) Moves(pos *chess.Position) ([]BookMove, error) {
+	key, err := Key(pos)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := b.Entries(key)
+	if err != nil {
+		return nil, err
+	}
+
+	legal := map[uint16]chess.Move{}
+	for _, m := range pos.ValidMoves() {
+		legal[encodeMove(&m)] = m
+	}
+
+	var moves []BookMove
+	for _, e := range entries {
+		m, ok := legal[e.Move]
+		if !ok {
+			continue
+		}
+		moves = append(moves, BookMove{Move: &m, Weight: e.Weight, Learn: e.Learn})
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Weight > moves[j].Weight
+	})
+	return moves, nil
+}
+
+func (b *BookReader) entryAt(i int64) (chess.PolyglotEntry, error) {
+	var buf [entrySize]byte
+	if _, err := b.r.ReadAt(buf[:], i*entrySize); err != nil {
+		return chess.PolyglotEntry{}, err
+	}
+	return chess.PolyglotEntry{
+		Key:    binary.BigEndian.Uint64(buf[0:8]),
+		Move:   binary.BigEndian.Uint16(buf[8:10]),
+		Weight: binary.BigEndian.Uint16(buf[10:12]),
+		Learn:  binary.BigEndian.Uint32(buf[12:16]),
+	}, nil
+}
+
+// encodeMove converts a move to its Polyglot encoding. Polyglot writes
+// castling as the king capturing its own rook (e1h1 rather than e1g1).
+func encodeMove(m *chess.Move) uint16 {
+	from, to := m.S1(), m.S2()
+	switch {
+	case m.HasTag(chess.KingSideCastle):
+		to = chess.NewSquare(chess.FileH, from.Rank())
+	case m.HasTag(chess.QueenSideCastle):
+		to = chess.NewSquare(chess.FileA, from.Rank())
+	}
+
+	var promo uint16
+	switch m.Promo() {
+	case chess.Knight:
+		promo = 1
+	case chess.Bishop:
+		promo = 2
+	case chess.Rook:
+		promo = 3
+	case chess.Queen:
+		promo = 4
+	}
+
+	return uint16(to.File()) |
+		uint16(to.Rank())<<3 |
+		uint16(from.File())<<6 |
+		uint16(from.Rank())<<9 |
+		promo<<12
+}
+
+// BookBuilder collects moves from games and writes them out as a Polyglot
+// book. Each move is weighted by how it scored for the side playing it:
+// two points per win and one per draw, with unfinished games counted as
+// draws.
+type BookBuilder struct {
+	// MaxPly is the number of plies read from each game; 0 means no limit.
+	MaxPly int
+	// MinGames is the number of distinct games a move must appear in to be
+	// written to the book.
+	MinGames int
+
+	stats map[uint64]map[uint16]*moveStats
+	games int
+}
+
+type moveStats struct {
+	score    int
+	games    int
+	lastGame int
+}
+
+// NewBookBuilder creates a builder reading maxPly plies of each game and
+// keeping moves played in at least minGames games.
+func NewBookBuilder(maxPly, minGames int) *BookBuilder {
+	return &BookBuilder{
+		MaxPly:   maxPly,
+		MinGames: minGames,
+		stats:    map[uint64]map[uint16]*moveStats{},
+	}
+}
+
+// AddGame adds the main line of game to the book.
+func (b *BookBuilder) AddGame(game *chess.Game) error {
+	b.games++
+
+	var points [2]int // indexed by mover: 0 for White, 1 for Black
+	switch game.Outcome() {
+	case chess.WhiteWon:
+		points = [2]int{2, 0}
+	case chess.BlackWon:
+		points = [2]int{0, 2}
+	default:
+		points = [2]int{1, 1}
+	}
+
+	positions := game.Positions()
+	for i, move := range game.Moves() {
+		if b.MaxPly > 0 && i >= b.MaxPly {
+			break
+		}
+		pos := positions[i]
+		key, err := Key(pos)
+		if err != nil {
+			return err
+		}
+
+		byMove, ok := b.stats[key]
+		if !ok {
+			byMove = map[uint16]*moveStats{}
+			b.stats[key] = byMove
+		}
+		code := encodeMove(move)
+		s, ok := byMove[code]
+		if !ok {
+			s = &moveStats{}
+			byMove[code] = s
+		}
+
+		mover := 0
+		if pos.Turn() == chess.Black {
+			mover = 1
+		}
+		s.score += points[mover]
+		if s.lastGame != b.games {
+			s.games++
+			s.lastGame = b.games
+		}
+	}
+	return nil
+}
+
+// AddPGN adds every game read from r. Games that fail to parse are logged
+// and skipped. It returns the number of games added.
+func (b *BookBuilder) AddPGN(r io.Reader) (int, error) {
+	scanner := chess.NewScanner(r)
+	added := 0
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.Printf("Skipping game: %v\n", err)
+			continue
+		}
+		if err := b.AddGame(game); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// Entries returns the book entries sorted the way Polyglot expects: by key,
+// then by descending weight. Weights that do not fit in 16 bits are scaled
+// down per position, keeping their proportions.
+func (b *BookBuilder) Entries() []chess.PolyglotEntry {
+	var entries []chess.PolyglotEntry
+	for key, byMove := range b.stats {
+		maxScore := 0
+		for _, s := range byMove {
+			if s.score > maxScore {
+				maxScore = s.score
+			}
+		}
+		for code, s := range byMove {
+			if s.games < b.MinGames || s.score == 0 {
+				continue
+			}
+			weight := s.score
+			if maxScore > 0xffff {
+				weight = max(1, s.score*0xffff/maxScore)
+			}
+			entries = append(entries, chess.PolyglotEntry{Key: key, Move: code, Weight: uint16(weight)})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		if entries[i].Weight != entries[j].Weight {
+			return entries[i].Weight > entries[j].Weight
+		}
+		return entries[i].Move < entries[j].Move
+	})
+	return entries
+}
+
+// WriteTo writes the book in Polyglot .bin format.
+func (b *BookBuilder) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	var buf [entrySize]byte
+	for _, e := range b.Entries() {
+		binary.BigEndian.PutUint64(buf[0:8], e.Key)
+		binary.BigEndian.PutUint16(buf[8:10], e.Move)
+		binary.BigEndian.PutUint16(buf[10:12], e.Weight)
+		binary.BigEndian.PutUint32(buf[12:16], e.Learn)
+		n, err := w.Write(buf[:])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
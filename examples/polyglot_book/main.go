@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -pgn games.pgn -out book.bin -depth 16 -min 3   # build a book
+//	go run . -book book.bin -fen "<FEN>"                     # query a book
+//
+// Without flags a small book is built from a few games and queried.
+func main() {
+	pgnPath := flag.String("pgn", "", "PGN collection to build a book from")
+	outPath := flag.String("out", "book.bin", "where to write the built book")
+	depth := flag.Int("depth", 20, "number of plies of each game to include (0 for all)")
+	minGames := flag.Int("min", 1, "minimum number of games a move must appear in")
+	bookPath := flag.String("book", "", "Polyglot book to query")
+	fen := flag.String("fen", "", "position to look up (default: starting position)")
+	flag.Parse()
+
+	switch {
+	case *pgnPath != "":
+		if err := buildBook(*pgnPath, *outPath, *depth, *minGames); err != nil {
+			log.Fatal(err)
+		}
+	case *bookPath != "":
+		pos := chess.StartingPosition()
+		if *fen != "" {
+			fenGame, err := chess.FEN(*fen)
+			if err != nil {
+				log.Fatal(err)
+			}
+			pos = chess.NewGame(fenGame).Position()
+		}
+		book, err := OpenBook(*bookPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer book.Close()
+		if err := printBookMoves(book, pos); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		runDemo()
+	}
+}
+
+func buildBook(pgnPath, outPath string, depth, minGames int) error {
+	in, err := os.Open(pgnPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	builder := NewBookBuilder(depth, minGames)
+	games, err := builder.AddPGN(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	n, err := builder.WriteTo(out)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	fmt.Printf("Wrote %d entries from %d games to %s\n", n/entrySize, games, outPath)
+	return out.Close()
+}
+
+// printBookMoves prints the book moves for pos in SAN with their share of
+// the total weight.
+func printBookMoves(book *BookReader, pos *chess.Position) error {
+	moves, err := book.Moves(pos)
+	if err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		fmt.Println("  (no book moves)")
+		return nil
+	}
+
+	total := 0
+	for _, m := range moves {
+		total += int(m.Weight)
+	}
+	for _, m := range moves {
+		san := chess.AlgebraicNotation{}.Encode(pos, m.Move)
+		fmt.Printf("  %-6s weight %5d (%4.1f%%)\n", san, m.Weight, 100*float64(m.Weight)/float64(total))
+	}
+	return nil
+}
+
+func runDemo() {
+	fmt.Println("=== Polyglot Opening Book Example ===")
+
+	// Example 1: Polyglot keys
+	fmt.Println("\n1. Polyglot Keys")
+	game := chess.NewGame()
+	for _, move := range []string{"", "e4", "d5", "e5", "f5"} {
+		if move != "" {
+			if err := game.PushMove(move, nil); err != nil {
+				log.Fatal(err)
+			}
+		}
+		key, err := Key(game.Position())
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("  %-40s %016x\n", strings.Join(moveList(game), " ")+":", key)
+	}
+
+	// Example 2: Building a book from a PGN collection
+	fmt.Println("\n2. Building a Book (depth 8, min 2 games)")
+	builder := NewBookBuilder(8, 2)
+	games, err := builder.AddPGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := builder.WriteTo(&buf); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d games -> %d entries (%d bytes)\n", games, buf.Len()/entrySize, buf.Len())
+
+	dir, err := os.MkdirTemp("", "polyglot_book")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bookPath := filepath.Join(dir, "demo.bin")
+	if err := os.WriteFile(bookPath, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 3: Looking up weighted moves
+	book, err := OpenBook(bookPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer book.Close()
+
+	fmt.Println("\n3. Book Moves From the Starting Position")
+	if err := printBookMoves(book, chess.StartingPosition()); err != nil {
+		log.Fatal(err)
+	}
+
+	game = chess.NewGame()
+	game.PushMove("e4", nil)
+	fmt.Println("\n   After 1. e4")
+	if err := printBookMoves(book, game.Position()); err != nil {
+		log.Fatal(err)
+	}
+
+	game.PushMove("e5", nil)
+	game.PushMove("Nf3", nil)
+	game.PushMove("Nc6", nil)
+	game.PushMove("Bc4", nil)
+	game.PushMove("Bc5", nil)
+	fmt.Println("\n   After 1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 (castling is stored as e1h1)")
+	if err := printBookMoves(book, game.Position()); err != nil {
+		log.Fatal(err)
+	}
+
+	// Example 4: The book is readable by the chess package's own loader
+	fmt.Println("\n4. Cross-Check With chess.LoadFromBytes")
+	loaded, err := chess.LoadFromBytes(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	key, _ := Key(chess.StartingPosition())
+	fmt.Printf("chess.PolyglotBook has %d entries for the starting position\n", len(loaded.FindMoves(key)))
+}
+
+func moveList(game *chess.Game) []string {
+	positions := game.Positions()
+	var sans []string
+	for i, m := range game.Moves() {
+		sans = append(sans, chess.AlgebraicNotation{}.Encode(positions[i], m))
+	}
+	if len(sans) == 0 {
+		return []string{"start"}
+	}
+	return sans
+}
+
+// The scanner splits games on the Event tag, so every game carries one.
+const demoPGN = `[Event "Book Game 1"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 1-0
+
+[Event "Book Game 2"]
+[Result "1/2-1/2"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 Nf6 4. O-O Nxe4 1/2-1/2
+
+[Event "Book Game 3"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. O-O Nf6 5. d3 d6 1-0
+
+[Event "Book Game 4"]
+[Result "0-1"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. O-O d6 5. c3 Nf6 0-1
+
+[Event "Book Game 5"]
+[Result "1-0"]
+
+1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 1-0
+
+[Event "Book Game 6"]
+[Result "0-1"]
+
+1. e4 c5 2. Nf3 Nc6 3. d4 cxd4 4. Nxd4 Nf6 0-1
+
+[Event "Book Game 7"]
+[Result "1-0"]
+
+1. d4 d5 2. c4 e6 3. Nc3 Nf6 1-0
+
+[Event "Book Game 8"]
+[Result "1/2-1/2"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 1/2-1/2
+`
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// glyphs is a 5x7 bitmap font covering the board coordinates, so the
+// renderer can label files and ranks without a font package.
+var glyphs = map[rune][7]string{
+	'a': {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "####."},
+	'c': {".....", ".....", ".###.", "#....", "#....", "#...#", ".###."},
+	'd': {"....#", "....#", ".####", "#...#", "#...#", "#...#", ".####"},
+	'e': {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f': {"..##.", ".#..#", ".#...", "###..", ".#...", ".#...", ".#..."},
+	'g': {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h': {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+}
+
+// drawText draws s with its top left corner at (x, y), each font pixel
+// scale image pixels wide.
+func drawText(img draw.Image, x, y, scale int, s string, c color.Color) {
+	src := image.NewUniform(c)
+	for _, r := range s {
+		g, ok := glyphs[r]
+		if !ok {
+			x += 6 * scale
+			continue
+		}
+		for gy, row := range g {
+			for gx, bit := range row {
+				if bit != '#' {
+					continue
+				}
+				px := image.Rect(x+gx*scale, y+gy*scale, x+(gx+1)*scale, y+(gy+1)*scale)
+				draw.Draw(img, px, src, image.Point{}, draw.Over)
+			}
+		}
+		x += 6 * scale
+	}
+}
+
+// textWidth returns the width drawText needs for s.
+func textWidth(s string, scale int) int {
+	return (6*len(s) - 1) * scale
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Animation controls the timing and highlighting of an animated game.
+type Animation struct {
+	// Delay is how long each position is shown.
+	Delay time.Duration
+	// CommentDelay, when longer than Delay, is used instead after moves
+	// that carry a comment or a NAG, to give the viewer time to notice.
+	CommentDelay time.Duration
+	// FinalDelay is how long the final position is shown before the
+	// animation starts over.
+	FinalDelay time.Duration
+	// Highlight marks the from and to squares of each move; nil turns the
+	// highlighting off.
+	Highlight color.Color
+}
+
+// DefaultAnimation shows one position per second and highlights moves in
+// yellow.
+var DefaultAnimation = Animation{
+	Delay:        time.Second,
+	CommentDelay: 3 * time.Second,
+	FinalDelay:   5 * time.Second,
+	Highlight:    color.RGBA{255, 255, 0, 255},
+}
+
+// GIF writes the main line of game as an animated GIF, one frame per
+// position starting from the initial one. opts apply to every frame.
+func GIF(w io.Writer, game *chess.Game, anim Animation, opts ...Option) error {
+	positions := game.Positions()
+	moves := game.Moves()
+	if len(positions) == 0 {
+		return errors.New("game has no positions")
+	}
+
+	pal := framePalette(newRenderer(opts), anim.Highlight)
+	q := &quantizer{palette: pal, cache: map[color.RGBA]uint8{}}
+	out := &gif.GIF{LoopCount: 0}
+
+	for i, pos := range positions {
+		frameOpts := opts
+		delay := anim.Delay
+		if i > 0 {
+			m := moves[i-1]
+			if anim.Highlight != nil {
+				frameOpts = append(frameOpts[:len(frameOpts):len(frameOpts)], MarkSquares(anim.Highlight, m.S1(), m.S2()))
+			}
+			if (m.Comments() != "" || m.NAG() != "") && anim.CommentDelay > delay {
+				delay = anim.CommentDelay
+			}
+		}
+		if i == len(positions)-1 && anim.FinalDelay > delay {
+			delay = anim.FinalDelay
+		}
+
+		out.Image = append(out.Image, q.paletted(Render(pos.Board(), frameOpts...)))
+		out.Delay = append(out.Delay, int(delay/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, out)
+}
+
+// framePalette builds the GIF palette for a board: the square colours,
+// with and without the highlight, and the blends between each of them and
+// the piece colours that anti-aliased edges produce.
+func framePalette(r *renderer, highlight color.Color) color.Palette {
+	backgrounds := []color.RGBA{toRGBA(r.light), toRGBA(r.dark)}
+	if highlight != nil {
+		for _, bg := range backgrounds[:2] {
+			backgrounds = append(backgrounds, blend(bg, toRGBA(highlight), 51))
+		}
+	}
+	inks := []color.RGBA{
+		outlineColor,
+		pieceFill[chess.White], pieceFill[chess.Black],
+		pieceDetail[chess.White], pieceDetail[chess.Black],
+	}
+
+	const steps = 12
+	seen := map[color.RGBA]bool{}
+	var pal color.Palette
+	add := func(c color.RGBA) {
+		if !seen[c] && len(pal) < 256 {
+			seen[c] = true
+			pal = append(pal, c)
+		}
+	}
+	for _, bg := range backgrounds {
+		add(bg)
+	}
+	for _, ink := range inks {
+		add(ink)
+	}
+	for _, bg := range backgrounds {
+		for _, ink := range inks {
+			for s := 1; s < steps; s++ {
+				add(blend(bg, ink, uint8(255*s/steps)))
+			}
+		}
+	}
+	return pal
+}
+
+// quantizer maps frames onto a palette, remembering the nearest palette
+// entry of every colour it has seen; boards use few distinct colours, so
+// this is much faster than searching the palette for every pixel.
+type quantizer struct {
+	palette color.Palette
+	cache   map[color.RGBA]uint8
+}
+
+func (q *quantizer) paletted(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+	p := image.NewPaletted(b, q.palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			idx, ok := q.cache[c]
+			if !ok {
+				idx = uint8(q.palette.Index(c))
+				q.cache[c] = idx
+			}
+			p.SetColorIndex(x, y, idx)
+		}
+	}
+	return p
+}
+
+func toRGBA(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// blend lays fg over bg with the given opacity out of 255.
+func blend(bg, fg color.RGBA, alpha uint8) color.RGBA {
+	a := int(alpha)
+	mixc := func(b, f uint8) uint8 { return uint8((int(b)*(255-a) + int(f)*a) / 255) }
+	return color.RGBA{mixc(bg.R, fg.R), mixc(bg.G, fg.G), mixc(bg.B, fg.B), 255}
+}
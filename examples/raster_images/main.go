@@ -0,0 +1,216 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -fen "<FEN>" -out board.png -size 80 -black -mark e2,e4
+//	go run . -fen "<FEN>" -out board.jpg -quality 85
+//	go run . -pgn game.pgn -out game.gif -delay 800ms -comment-delay 3s
+//
+// The output format follows the file extension; a .gif is an animation of
+// the game read from -pgn. Without flags the demo renders a few boards and
+// an animated game into the current directory.
+func main() {
+	fen := flag.String("fen", "", "position to render (default: starting position)")
+	outPath := flag.String("out", "", "output file, .png or .jpg")
+	size := flag.Int("size", 45, "square size in pixels")
+	black := flag.Bool("black", false, "draw the board from Black's side")
+	marks := flag.String("mark", "", "comma separated squares to highlight, e.g. e2,e4")
+	quality := flag.Int("quality", 90, "JPEG quality (1-100)")
+	pgnPath := flag.String("pgn", "", "PGN file whose first game is animated into a .gif")
+	delay := flag.Duration("delay", DefaultAnimation.Delay, "time each position is shown in a GIF")
+	commentDelay := flag.Duration("comment-delay", DefaultAnimation.CommentDelay, "time shown after moves with a comment or NAG")
+	noHighlight := flag.Bool("no-highlight", false, "do not highlight the squares of each move in a GIF")
+	flag.Parse()
+
+	if *outPath == "" {
+		runDemo()
+		return
+	}
+
+	board := chess.StartingPosition().Board()
+	if *fen != "" {
+		opt, err := chess.FEN(*fen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		board = chess.NewGame(opt).Position().Board()
+	}
+
+	opts := []Option{SquareSize(*size)}
+	if *black {
+		opts = append(opts, Perspective(chess.Black))
+	}
+	if *marks != "" {
+		sqs, err := parseSquares(*marks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, MarkSquares(color.RGBA{255, 255, 0, 255}, sqs...))
+	}
+
+	if strings.EqualFold(filepath.Ext(*outPath), ".gif") {
+		anim := DefaultAnimation
+		anim.Delay = *delay
+		anim.CommentDelay = *commentDelay
+		if *noHighlight {
+			anim.Highlight = nil
+		}
+		if err := writeGIF(*outPath, *pgnPath, anim, opts...); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Animation saved to: %s\n", *outPath)
+		return
+	}
+
+	if err := writeImage(*outPath, board, *quality, opts...); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Board saved to: %s\n", *outPath)
+}
+
+// writeImage renders the board to path as PNG or JPEG, by extension.
+func writeImage(path string, b *chess.Board, quality int, opts ...Option) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		err = JPEG(f, b, quality, opts...)
+	case ".png":
+		err = PNG(f, b, opts...)
+	default:
+		err = fmt.Errorf("unsupported image format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeGIF animates the first game of the PGN file at pgnPath into path.
+func writeGIF(path, pgnPath string, anim Animation, opts ...Option) error {
+	if pgnPath == "" {
+		return fmt.Errorf("a .gif output needs a game, use -pgn")
+	}
+	in, err := os.Open(pgnPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	pgn, err := chess.PGN(in)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := GIF(f, chess.NewGame(pgn), anim, opts...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func parseSquares(list string) ([]chess.Square, error) {
+	var sqs []chess.Square
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if len(name) != 2 || name[0] < 'a' || name[0] > 'h' || name[1] < '1' || name[1] > '8' {
+			return nil, fmt.Errorf("invalid square %q", name)
+		}
+		sqs = append(sqs, chess.NewSquare(chess.File(name[0]-'a'), chess.Rank(name[1]-'1')))
+	}
+	return sqs, nil
+}
+
+func runDemo() {
+	fmt.Println("=== Raster Images Example ===")
+	game := chess.NewGame()
+
+	// Make some moves to get an interesting position
+	moves := []string{"e4", "e5", "Nf3", "Nc6", "Bb5"}
+	for _, move := range moves {
+		game.PushMove(move, nil)
+	}
+	board := game.Position().Board()
+
+	// Example 1: PNG with the last move marked
+	fmt.Println("\n1. PNG With Marked Squares")
+	pngPath := filepath.Join(".", "ruy_lopez.png")
+	err := writeImage(pngPath, board, 0,
+		MarkSquares(color.RGBA{255, 255, 0, 255}, chess.F1, chess.B5), // Yellow for the last move
+	)
+	if err != nil {
+		log.Printf("Error writing PNG: %v\n", err)
+		return
+	}
+	fmt.Printf("Position saved to: %s\n", pngPath)
+
+	// Example 2: JPEG from Black's side with bigger squares
+	fmt.Println("\n2. JPEG From Black's Perspective")
+	jpegPath := filepath.Join(".", "ruy_lopez_black.jpg")
+	err = writeImage(jpegPath, board, 90, Perspective(chess.Black), SquareSize(80))
+	if err != nil {
+		log.Printf("Error writing JPEG: %v\n", err)
+		return
+	}
+	fmt.Printf("Position saved to: %s\n", jpegPath)
+
+	// Example 3: Working with the image.Image directly
+	fmt.Println("\n3. Rendering to an image.Image")
+	img := Render(board, SquareSize(20), Coordinates(false),
+		SquareColors(color.RGBA{240, 240, 240, 255}, color.RGBA{120, 150, 90, 255}))
+	fmt.Printf("Image bounds: %v\n", img.Bounds())
+	fmt.Printf("Colour of the h1 corner: %v\n", img.At(img.Bounds().Max.X-1, img.Bounds().Max.Y-1))
+
+	// Example 4: Animated GIF of an annotated game
+	fmt.Println("\n4. Animated GIF of a Game")
+	pgn, err := chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	gifPath := filepath.Join(".", "opera_game.gif")
+	f, err := os.Create(gifPath)
+	if err != nil {
+		log.Printf("Error creating GIF: %v\n", err)
+		return
+	}
+	anim := DefaultAnimation
+	anim.Delay = 700 * time.Millisecond // Pauses longer on the annotated moves
+	err = GIF(f, chess.NewGame(pgn), anim, SquareSize(40))
+	f.Close()
+	if err != nil {
+		log.Printf("Error writing GIF: %v\n", err)
+		return
+	}
+	fmt.Printf("Game saved to: %s\n", gifPath)
+}
+
+const demoPGN = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 $2 4. dxe5 Bxf3 5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7
+8. Nc3 c6 9. Bg5 b5 $2 10. Nxb5 cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8
+13. Rxd7 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7 16. Qb8+ { A queen sacrifice. } Nxb8
+17. Rd8# 1-0`
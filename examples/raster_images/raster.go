@@ -0,0 +1,151 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Option customises a rendered board. The options mirror those of the
+// image package's SVG function; the image package's own options cannot be
+// reused because their argument type is unexported.
+type Option func(*renderer)
+
+// SquareSize sets the size of a square in pixels. The default is 45, the
+// size image.SVG uses.
+func SquareSize(px int) Option {
+	return func(r *renderer) {
+		if px > 0 {
+			r.size = px
+		}
+	}
+}
+
+// SquareColors changes the light and dark square colours.
+func SquareColors(light, dark color.Color) Option {
+	return func(r *renderer) {
+		r.light = light
+		r.dark = dark
+	}
+}
+
+// MarkSquares tints the given squares with the colour, e.g. to show the
+// previous move.
+func MarkSquares(c color.Color, sqs ...chess.Square) Option {
+	return func(r *renderer) {
+		for _, sq := range sqs {
+			r.marks[sq] = c
+		}
+	}
+}
+
+// Perspective draws the board from the given side. White is the default.
+func Perspective(c chess.Color) Option {
+	return func(r *renderer) {
+		r.perspective = c
+	}
+}
+
+// Coordinates turns the file and rank labels on or off. They are on by
+// default, like in image.SVG.
+func Coordinates(on bool) Option {
+	return func(r *renderer) {
+		r.coordinates = on
+	}
+}
+
+type renderer struct {
+	size        int
+	light       color.Color
+	dark        color.Color
+	marks       map[chess.Square]color.Color
+	perspective chess.Color
+	coordinates bool
+}
+
+func newRenderer(opts []Option) *renderer {
+	r := &renderer{
+		size:        45,
+		light:       color.RGBA{235, 209, 166, 255},
+		dark:        color.RGBA{165, 117, 81, 255},
+		marks:       map[chess.Square]color.Color{},
+		perspective: chess.White,
+		coordinates: true,
+	}
+	for _, op := range opts {
+		op(r)
+	}
+	return r
+}
+
+// Render draws the board as an image 8 squares wide and high.
+func Render(b *chess.Board, opts ...Option) *image.RGBA {
+	r := newRenderer(opts)
+	img := image.NewRGBA(image.Rect(0, 0, 8*r.size, 8*r.size))
+	r.draw(img, image.Point{}, b)
+	return img
+}
+
+// PNG writes the board as a PNG image.
+func PNG(w io.Writer, b *chess.Board, opts ...Option) error {
+	return png.Encode(w, Render(b, opts...))
+}
+
+// JPEG writes the board as a JPEG image with the given quality (1-100).
+func JPEG(w io.Writer, b *chess.Board, quality int, opts ...Option) error {
+	return jpeg.Encode(w, Render(b, opts...), &jpeg.Options{Quality: quality})
+}
+
+// draw renders the board onto img with its top left corner at origin.
+func (r *renderer) draw(img draw.Image, origin image.Point, b *chess.Board) {
+	squares := b.SquareMap()
+	scale := max(1, r.size/30)
+	for row := range 8 {
+		for col := range 8 {
+			sq := r.squareAt(col, row)
+			rect := image.Rect(col*r.size, row*r.size, (col+1)*r.size, (row+1)*r.size).Add(origin)
+
+			light := (int(sq.File())+int(sq.Rank()))%2 == 1
+			bg, fg := r.dark, r.light
+			if light {
+				bg, fg = r.light, r.dark
+			}
+			draw.Draw(img, rect, image.NewUniform(bg), image.Point{}, draw.Src)
+			if c, ok := r.marks[sq]; ok {
+				// image.SVG lays marks over the square at 20% opacity.
+				mask := image.NewUniform(color.Alpha{51})
+				draw.DrawMask(img, rect, image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+			}
+
+			if p := squares[sq]; p != chess.NoPiece {
+				draw.Draw(img, rect, pieceImage(p, r.size), image.Point{}, draw.Over)
+			}
+
+			if !r.coordinates {
+				continue
+			}
+			pad := max(1, r.size/20)
+			if col == 0 {
+				drawText(img, rect.Min.X+pad, rect.Min.Y+pad, scale, sq.Rank().String(), fg)
+			}
+			if row == 7 {
+				file := sq.File().String()
+				drawText(img, rect.Max.X-pad-textWidth(file, scale), rect.Max.Y-pad-7*scale, scale, file, fg)
+			}
+		}
+	}
+}
+
+// squareAt returns the square shown in the given column and row, counted
+// from the top left corner.
+func (r *renderer) squareAt(col, row int) chess.Square {
+	if r.perspective == chess.Black {
+		return chess.NewSquare(chess.File(7-col), chess.Rank(row))
+	}
+	return chess.NewSquare(chess.File(col), chess.Rank(7-row))
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/corentings/chess/v2"
+)
+
+// The piece sprites are drawn from simple shapes in a unit square (x to
+// the right, y down) rather than loaded from files, so the renderer needs
+// nothing but the standard library and works offline. Each shape reports
+// its signed distance to a point, negative inside, which gives both the
+// fill (distance < 0) and an outline of any width (distance < width).
+
+type shape interface {
+	dist(x, y float64) float64
+}
+
+type circle struct{ cx, cy, r float64 }
+
+func (c circle) dist(x, y float64) float64 {
+	return math.Hypot(x-c.cx, y-c.cy) - c.r
+}
+
+type polygon []point
+
+type point struct{ x, y float64 }
+
+func (p polygon) dist(x, y float64) float64 {
+	d := math.Inf(1)
+	inside := false
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		d = math.Min(d, segmentDist(x, y, a, b))
+		if (a.y > y) != (b.y > y) && x < a.x+(y-a.y)*(b.x-a.x)/(b.y-a.y) {
+			inside = !inside
+		}
+	}
+	if inside {
+		return -d
+	}
+	return d
+}
+
+func segmentDist(x, y float64, a, b point) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	t := ((x-a.x)*dx + (y-a.y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(x-(a.x+t*dx), y-(a.y+t*dy))
+}
+
+func rect(x0, y0, x1, y1 float64) polygon {
+	return polygon{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+}
+
+// ellipse approximates an ellipse with a polygon.
+func ellipse(cx, cy, rx, ry float64) polygon {
+	const n = 32
+	p := make(polygon, n)
+	for i := range p {
+		a := 2 * math.Pi * float64(i) / n
+		p[i] = point{cx + rx*math.Cos(a), cy + ry*math.Sin(a)}
+	}
+	return p
+}
+
+// sprite is the drawing of one piece type: the body is filled with the
+// piece colour and outlined, the details are drawn on top in the outline
+// colour of the opposite side.
+type sprite struct {
+	body    []shape
+	details []shape
+}
+
+// base is the pedestal shared by all pieces.
+var base = polygon{{0.22, 0.76}, {0.78, 0.76}, {0.82, 0.88}, {0.18, 0.88}}
+
+// baseLine separates the pedestal from the rest of the piece.
+var baseLine = rect(0.26, 0.755, 0.74, 0.775)
+
+var sprites = map[chess.PieceType]sprite{
+	chess.Pawn: {
+		body: []shape{
+			base,
+			polygon{{0.42, 0.44}, {0.58, 0.44}, {0.68, 0.76}, {0.32, 0.76}},
+			ellipse(0.5, 0.46, 0.14, 0.04),
+			circle{0.5, 0.33, 0.12},
+		},
+		details: []shape{baseLine},
+	},
+	chess.Rook: {
+		body: []shape{
+			base,
+			rect(0.32, 0.38, 0.68, 0.76),
+			polygon{
+				{0.25, 0.16}, {0.35, 0.16}, {0.35, 0.23}, {0.45, 0.23}, {0.45, 0.16},
+				{0.55, 0.16}, {0.55, 0.23}, {0.65, 0.23}, {0.65, 0.16}, {0.75, 0.16},
+				{0.75, 0.32}, {0.68, 0.38}, {0.32, 0.38}, {0.25, 0.32},
+			},
+		},
+		details: []shape{baseLine, rect(0.32, 0.37, 0.68, 0.39), rect(0.31, 0.655, 0.69, 0.675)},
+	},
+	chess.Knight: {
+		body: []shape{
+			base,
+			polygon{
+				{0.28, 0.76}, {0.74, 0.76}, {0.74, 0.56}, {0.70, 0.40}, {0.62, 0.27},
+				{0.52, 0.19}, {0.46, 0.12}, {0.42, 0.21}, {0.34, 0.26}, {0.24, 0.42},
+				{0.17, 0.52}, {0.21, 0.58}, {0.29, 0.55}, {0.36, 0.49}, {0.45, 0.47},
+				{0.40, 0.57}, {0.30, 0.68},
+			},
+		},
+		details: []shape{baseLine, circle{0.40, 0.31, 0.028}, rect(0.19, 0.515, 0.23, 0.535)},
+	},
+	chess.Bishop: {
+		body: []shape{
+			base,
+			polygon{{0.40, 0.62}, {0.60, 0.62}, {0.66, 0.76}, {0.34, 0.76}},
+			ellipse(0.5, 0.62, 0.17, 0.045),
+			ellipse(0.5, 0.42, 0.15, 0.2),
+			polygon{{0.5, 0.15}, {0.58, 0.27}, {0.42, 0.27}},
+			circle{0.5, 0.13, 0.045},
+		},
+		details: []shape{
+			baseLine,
+			polygon{{0.52, 0.30}, {0.56, 0.32}, {0.47, 0.48}, {0.43, 0.46}},
+			rect(0.34, 0.60, 0.66, 0.62),
+		},
+	},
+	chess.Queen: {
+		body: []shape{
+			base,
+			polygon{
+				{0.20, 0.30}, {0.33, 0.52}, {0.37, 0.24}, {0.45, 0.50}, {0.5, 0.20},
+				{0.55, 0.50}, {0.63, 0.24}, {0.67, 0.52}, {0.80, 0.30}, {0.71, 0.76},
+				{0.29, 0.76},
+			},
+			circle{0.20, 0.28, 0.045},
+			circle{0.37, 0.22, 0.045},
+			circle{0.5, 0.18, 0.045},
+			circle{0.63, 0.22, 0.045},
+			circle{0.80, 0.28, 0.045},
+		},
+		details: []shape{baseLine, rect(0.29, 0.645, 0.71, 0.665)},
+	},
+	chess.King: {
+		body: []shape{
+			base,
+			polygon{{0.26, 0.40}, {0.74, 0.40}, {0.68, 0.76}, {0.32, 0.76}},
+			ellipse(0.5, 0.40, 0.24, 0.09),
+			circle{0.5, 0.33, 0.09},
+			rect(0.465, 0.07, 0.535, 0.26),
+			rect(0.40, 0.12, 0.60, 0.18),
+		},
+		details: []shape{baseLine, rect(0.29, 0.645, 0.71, 0.665), rect(0.28, 0.45, 0.72, 0.47)},
+	},
+}
+
+var (
+	pieceFill = map[chess.Color]color.RGBA{
+		chess.White: {255, 255, 255, 255},
+		chess.Black: {30, 30, 30, 255},
+	}
+	pieceDetail = map[chess.Color]color.RGBA{
+		chess.White: {0, 0, 0, 255},
+		chess.Black: {230, 230, 230, 255},
+	}
+	outlineColor = color.RGBA{0, 0, 0, 255}
+)
+
+const (
+	outlineWidth = 0.03 // in units of the square size
+	detailWidth  = 0.004
+	supersample  = 4 // samples per pixel along each axis
+)
+
+type spriteKey struct {
+	piece chess.Piece
+	size  int
+}
+
+var spriteCache sync.Map // spriteKey -> *image.RGBA
+
+// pieceImage returns the sprite of p drawn on a transparent size x size
+// image. Sprites are cached, as rendering many boards at one size is the
+// common case.
+func pieceImage(p chess.Piece, size int) *image.RGBA {
+	key := spriteKey{p, size}
+	if img, ok := spriteCache.Load(key); ok {
+		return img.(*image.RGBA)
+	}
+
+	sp := sprites[p.Type()]
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	const samples = supersample * supersample
+	for py := range size {
+		for px := range size {
+			var outline, fill, detail int
+			for sy := range supersample {
+				for sx := range supersample {
+					x := (float64(px) + (float64(sx)+0.5)/supersample) / float64(size)
+					y := (float64(py) + (float64(sy)+0.5)/supersample) / float64(size)
+					d := union(sp.body, x, y)
+					switch {
+					case d < 0 && union(sp.details, x, y) < detailWidth:
+						detail++
+					case d < 0:
+						fill++
+					case d < outlineWidth:
+						outline++
+					}
+				}
+			}
+			if outline+fill+detail == 0 {
+				continue
+			}
+			img.SetRGBA(px, py, mix([]weighted{
+				{outlineColor, outline},
+				{pieceFill[p.Color()], fill},
+				{pieceDetail[p.Color()], detail},
+			}, samples))
+		}
+	}
+
+	actual, _ := spriteCache.LoadOrStore(key, img)
+	return actual.(*image.RGBA)
+}
+
+func union(shapes []shape, x, y float64) float64 {
+	d := math.Inf(1)
+	for _, s := range shapes {
+		d = math.Min(d, s.dist(x, y))
+	}
+	return d
+}
+
+type weighted struct {
+	c color.RGBA
+	n int
+}
+
+// mix averages the colours by their sample counts out of total samples,
+// returning a premultiplied colour whose alpha is the covered fraction.
+func mix(colors []weighted, total int) color.RGBA {
+	var r, g, b, a int
+	for _, w := range colors {
+		r += int(w.c.R) * w.n
+		g += int(w.c.G) * w.n
+		b += int(w.c.B) * w.n
+		a += 255 * w.n
+	}
+	return color.RGBA{uint8(r / total), uint8(g / total), uint8(b / total), uint8(a / total)}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Drill quizzes a player on the moves of their side in repertoire lines.
+// The opponent's moves are played for them.
+type Drill struct {
+	Color chess.Color
+	// ShowBoard draws the position before each question.
+	ShowBoard bool
+	// Echo writes the answers to the output, for answers that do not
+	// come from a terminal.
+	Echo bool
+	in   *bufio.Scanner
+	out  io.Writer
+}
+
+// NewDrill returns a drill for color reading answers from in, one per
+// line, and writing the questions to out.
+func NewDrill(color chess.Color, in io.Reader, out io.Writer) *Drill {
+	return &Drill{Color: color, ShowBoard: true, in: bufio.NewScanner(in), out: out}
+}
+
+// errQuit is returned by Run when the player types q.
+var errQuit = errors.New("drill stopped")
+
+// Run plays line, asking for each move of the player's side, and returns
+// the SM-2 grade of the answers: 5 when all were right, 4 when a move was
+// another one of the repertoire, 2 after one wrong answer and 1 after more.
+// Answers that are not legal SAN moves are asked again without counting.
+func (d *Drill) Run(line Line) (int, error) {
+	mistakes, alternatives := 0, 0
+	for _, m := range line.Moves {
+		before := m.Parent().Position()
+		label := strings.TrimSpace(moveNumber(before)) + san(m)
+		if mover(m) != d.Color {
+			fmt.Fprintf(d.out, "Opponent plays %s\n", label)
+			continue
+		}
+		if d.ShowBoard {
+			fmt.Fprintln(d.out, before.Board().Draw2(d.Color, false))
+		}
+		for {
+			fmt.Fprintf(d.out, "%sYour move: ", moveNumber(before))
+			if !d.in.Scan() {
+				if err := d.in.Err(); err != nil {
+					return 0, err
+				}
+				return 0, io.EOF
+			}
+			answer := strings.TrimSpace(d.in.Text())
+			if d.Echo {
+				fmt.Fprintln(d.out, answer)
+			}
+			if answer == "q" {
+				return 0, errQuit
+			}
+			played, err := chess.AlgebraicNotation{}.Decode(before, answer)
+			if err != nil {
+				fmt.Fprintf(d.out, "%q is not a legal move here, try again\n", answer)
+				continue
+			}
+			switch {
+			case sameMove(played, m):
+				fmt.Fprintln(d.out, "Correct")
+			case inTree(played, m.Parent()):
+				alternatives++
+				fmt.Fprintf(d.out, "Also in your repertoire, but this line continues with %s\n", label)
+			default:
+				mistakes++
+				fmt.Fprintf(d.out, "Wrong: the repertoire move is %s\n", label)
+			}
+			break
+		}
+	}
+	switch {
+	case mistakes > 1:
+		return 1, nil
+	case mistakes == 1:
+		return 2, nil
+	case alternatives > 0:
+		return 4, nil
+	}
+	return 5, nil
+}
+
+func sameMove(a, b *chess.Move) bool {
+	return a.S1() == b.S1() && a.S2() == b.S2() && a.Promo() == b.Promo()
+}
+
+// inTree reports whether m is one of the continuations of parent.
+func inTree(m, parent *chess.Move) bool {
+	for _, child := range parent.Children() {
+		if sameMove(m, child) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -pgn repertoire.pgn -color black -state progress.json -lines 10
+//
+// The repertoire is a PGN game whose variations are the lines to learn.
+// Each session drills the lines that are due, typing the moves of -color
+// in SAN, and saves when each line should come back. Type q to stop.
+// Without flags the demo runs a scripted session on a small repertoire.
+func main() {
+	pgnPath := flag.String("pgn", "", "repertoire PGN file")
+	colorName := flag.String("color", "white", "side the repertoire is for: white or black")
+	statePath := flag.String("state", "repertoire_progress.json", "file the review schedule is kept in")
+	maxLines := flag.Int("lines", 10, "maximum number of lines per session")
+	flag.Parse()
+
+	if *pgnPath == "" {
+		runDemo()
+		return
+	}
+
+	color, err := parseColor(*colorName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	game, err := readRepertoire(*pgnPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schedule, err := LoadSchedule(*statePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	drill := NewDrill(color, os.Stdin, os.Stdout)
+	if err := session(game, schedule, *statePath, drill, time.Now(), *maxLines); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseColor(s string) (chess.Color, error) {
+	switch strings.ToLower(s) {
+	case "white", "w":
+		return chess.White, nil
+	case "black", "b":
+		return chess.Black, nil
+	}
+	return chess.NoColor, fmt.Errorf("invalid color %q", s)
+}
+
+func readRepertoire(path string) (*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pgn, err := chess.PGN(f)
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(pgn), nil
+}
+
+// session drills up to max due lines, saving the schedule after each one
+// so an interrupted session keeps its progress.
+func session(game *chess.Game, schedule *Schedule, statePath string, drill *Drill, now time.Time, max int) error {
+	lines := Lines(game, drill.Color)
+	due := schedule.Due(lines, now)
+	fmt.Printf("%d lines in the repertoire, %d due\n", len(lines), len(due))
+	if len(due) > max {
+		due = due[:max]
+	}
+	for i, line := range due {
+		fmt.Printf("\nLine %d of %d\n", i+1, len(due))
+		grade, err := drill.Run(line)
+		if errors.Is(err, errQuit) || errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		card := schedule.Review(line, grade, now)
+		fmt.Printf("%s\nGrade %d, next review in %d day(s)\n", line.Key, grade, card.Interval)
+		if err := schedule.Save(statePath); err != nil {
+			return err
+		}
+	}
+	if next := schedule.Next(lines); !next.IsZero() {
+		fmt.Printf("\nNext review: %s\n", next.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func runDemo() {
+	fmt.Println("=== Repertoire Trainer Example ===")
+
+	pgn, err := chess.PGN(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	game := chess.NewGame(pgn)
+
+	// Example 1: The lines of a repertoire for Black
+	fmt.Println("\n1. Repertoire Lines")
+	for _, line := range Lines(game, chess.Black) {
+		fmt.Printf("  %s\n", line.Key)
+	}
+
+	// Example 2: A first session, answering from a script instead of the
+	// keyboard
+	fmt.Println("\n2. First Session")
+	dir, err := os.MkdirTemp("", "repertoire_trainer")
+	if err != nil {
+		log.Printf("Error creating a directory for the schedule: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	statePath := filepath.Join(dir, "demo_progress.json")
+	schedule, err := LoadSchedule(statePath)
+	if err != nil {
+		log.Printf("Error loading schedule: %v\n", err)
+		return
+	}
+	answers := strings.Join([]string{
+		"c5", "d6", "cxd4", "Nf6", "g6", // the Dragon, all right
+		"c5", "d6", "cxd4", "Nf6", "g6", // wrong: against 5.f3 the line goes 5...e5
+		"c5", "Nf6", "Qxd5", // 2...Nf6 is in the repertoire, but this line is 2...d5
+		"c5", "Nf6", "Nxe5", "Nd5", // Nxe5 is not a legal move, asked again
+		"c5", "Nc6", "q", // stop before the last line
+	}, "\n")
+	drill := NewDrill(chess.Black, strings.NewReader(answers), os.Stdout)
+	drill.ShowBoard, drill.Echo = false, true
+	day := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	if err := session(game, schedule, statePath, drill, day, 10); err != nil {
+		log.Printf("Error during session: %v\n", err)
+		return
+	}
+
+	// Example 3: The schedule read back the next day
+	fmt.Println("\n3. The Next Day")
+	schedule, err = LoadSchedule(statePath)
+	if err != nil {
+		log.Printf("Error loading schedule: %v\n", err)
+		return
+	}
+	day = day.AddDate(0, 0, 1)
+	for _, line := range Lines(game, chess.Black) {
+		card, ok := schedule.Cards[line.Key]
+		if !ok {
+			fmt.Printf("  new                          %s\n", line.Key)
+			continue
+		}
+		fmt.Printf("  due %s  ease %.2f  %s\n", card.Due.Format("2006-01-02"), card.Ease, line.Key)
+	}
+	due := schedule.Due(Lines(game, chess.Black), day)
+	fmt.Printf("%d lines due on %s\n", len(due), day.Format("2006-01-02"))
+
+	// Example 4: How the intervals grow, and shrink after a lapse
+	fmt.Println("\n4. Review Intervals")
+	card := NewCard()
+	for _, grade := range []int{5, 5, 4, 5, 1, 4, 5} {
+		card = card.Review(grade, day)
+		fmt.Printf("  grade %d: next review in %3d day(s), ease %.2f\n", grade, card.Interval, card.Ease)
+		day = card.Due
+	}
+}
+
+const demoPGN = `[Event "Sicilian repertoire for Black"]
+[Result "*"]
+
+1. e4 c5 2. Nf3 (2. c3 d5 (2... Nf6 3. e5 Nd5) 3. exd5 Qxd5) (2. Nc3 Nc6 3. g3 g6)
+2... d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 (5. f3 e5) 5... g6 *`
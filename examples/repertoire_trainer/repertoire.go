@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Line is one path of a repertoire, from the starting position to a move
+// without continuation.
+type Line struct {
+	// Key is the movetext of the line, e.g. "1.e4 c5 2.Nf3 d6"; it
+	// identifies the line in a Schedule.
+	Key   string
+	Moves []*chess.Move
+}
+
+// Lines returns the lines of the repertoire in game that contain at least
+// one move for color, in the order of the tree: main line first.
+func Lines(game *chess.Game, color chess.Color) []Line {
+	var lines []Line
+	var walk func(m *chess.Move, path []*chess.Move)
+	walk = func(m *chess.Move, path []*chess.Move) {
+		children := m.Children()
+		if len(children) == 0 {
+			if len(path) > 0 && hasMoveFor(path, color) {
+				moves := append([]*chess.Move(nil), path...)
+				lines = append(lines, Line{Key: movetext(moves), Moves: moves})
+			}
+			return
+		}
+		for _, child := range children {
+			walk(child, append(path, child))
+		}
+	}
+	walk(game.GetRootMove(), nil)
+	return lines
+}
+
+func hasMoveFor(moves []*chess.Move, color chess.Color) bool {
+	for _, m := range moves {
+		if mover(m) == color {
+			return true
+		}
+	}
+	return false
+}
+
+// mover returns the side that played m.
+func mover(m *chess.Move) chess.Color {
+	return m.Parent().Position().Turn()
+}
+
+// movetext returns moves as SAN with move numbers, e.g. "1.e4 c5 2.Nf3".
+func movetext(moves []*chess.Move) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = san(m)
+		if i == 0 || mover(m) == chess.White {
+			parts[i] = strings.TrimSpace(moveNumber(m.Parent().Position())) + parts[i]
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func san(m *chess.Move) string {
+	return chess.AlgebraicNotation{}.Encode(m.Parent().Position(), m)
+}
+
+// moveNumber returns the number written before a move from pos: "12. "
+// for White, "12... " for Black.
+func moveNumber(pos *chess.Position) string {
+	n := "1"
+	if fields := strings.Fields(pos.String()); len(fields) >= 6 {
+		n = fields[5]
+	}
+	if pos.Turn() == chess.White {
+		return n + ". "
+	}
+	return n + "... "
+}
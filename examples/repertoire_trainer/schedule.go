@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Card is the review state of one line, as in the SM-2 algorithm.
+type Card struct {
+	// Ease grows with easy reviews and shrinks with hard ones; it starts
+	// at 2.5 and never drops below 1.3.
+	Ease float64 `json:"ease"`
+	// Interval is the number of days until the next review.
+	Interval int `json:"interval"`
+	// Reps counts the successful reviews in a row.
+	Reps int       `json:"reps"`
+	Due  time.Time `json:"due"`
+}
+
+// NewCard returns the state of a line never reviewed.
+func NewCard() Card {
+	return Card{Ease: 2.5}
+}
+
+// Review returns the card after a review graded from 0 (forgotten) to 5
+// (perfect) at now. A grade below 3 starts the line over with a one day
+// interval; otherwise the interval goes 1, 6, then grows by the ease.
+func (c Card) Review(grade int, now time.Time) Card {
+	grade = max(0, min(5, grade))
+	if grade < 3 {
+		c.Reps = 0
+		c.Interval = 1
+	} else {
+		switch c.Reps {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Reps++
+	}
+	q := float64(5 - grade)
+	c.Ease = math.Max(1.3, c.Ease+0.1-q*(0.08+q*0.02))
+	c.Due = now.AddDate(0, 0, c.Interval)
+	return c
+}
+
+// Schedule holds the cards of the lines of a repertoire, keyed by
+// Line.Key.
+type Schedule struct {
+	Cards map[string]Card `json:"cards"`
+}
+
+// LoadSchedule reads a schedule saved by Save. A missing file is an empty
+// schedule, so the first session needs no setup.
+func LoadSchedule(path string) (*Schedule, error) {
+	s := &Schedule{Cards: map[string]Card{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Cards == nil {
+		s.Cards = map[string]Card{}
+	}
+	return s, nil
+}
+
+// Save writes the schedule as JSON.
+func (s *Schedule) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Card returns the card of line, or a new one.
+func (s *Schedule) Card(line Line) Card {
+	if c, ok := s.Cards[line.Key]; ok {
+		return c
+	}
+	return NewCard()
+}
+
+// Review records the grade of a review of line at now.
+func (s *Schedule) Review(line Line, grade int, now time.Time) Card {
+	c := s.Card(line).Review(grade, now)
+	s.Cards[line.Key] = c
+	return c
+}
+
+// Due returns the lines to review at now: those whose review date has
+// passed, most overdue first, followed by the lines never reviewed in
+// repertoire order.
+func (s *Schedule) Due(lines []Line, now time.Time) []Line {
+	var due, fresh []Line
+	for _, line := range lines {
+		c, ok := s.Cards[line.Key]
+		switch {
+		case !ok:
+			fresh = append(fresh, line)
+		case !c.Due.After(now):
+			due = append(due, line)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return s.Cards[due[i].Key].Due.Before(s.Cards[due[j].Key].Due)
+	})
+	return append(due, fresh...)
+}
+
+// Next returns the earliest review date of the lines, or the zero time
+// when none has been reviewed.
+func (s *Schedule) Next(lines []Line) time.Time {
+	var next time.Time
+	for _, line := range lines {
+		if c, ok := s.Cards[line.Key]; ok && (next.IsZero() || c.Due.Before(next)) {
+			next = c.Due
+		}
+	}
+	return next
+}
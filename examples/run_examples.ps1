@@ -25,7 +25,7 @@ foreach ($mainFile in $mainFiles) {
     
     try {
         # Run the example and capture output
-        $output = & go run main.go 2>&1
+        $output = & go run . 2>&1
         
         # Save output to log file
         $output | Out-File -FilePath $logFile -Encoding UTF8
@@ -36,7 +36,7 @@ while IFS= read -r main_file; do
     pushd "$(dirname "$main_file")" > /dev/null
     
     # Run the example and capture output
-    if output=$(go run main.go 2>&1); then
+    if output=$(go run . 2>&1); then
         # Save output to log file
         echo "$output" > "$log_file"
         print_success "Completed. Log saved to: $log_file"
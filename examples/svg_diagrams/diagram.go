@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/image"
+)
+
+// Option customises a diagram. The board itself is drawn by image.SVG;
+// the options of this package add drawings on top of it. image.SVG's own
+// options cannot be taken directly because their argument type is
+// unexported, so the ones needed here are mirrored.
+type Option func(*diagram)
+
+// Perspective draws the board from the given side. White is the default.
+func Perspective(c chess.Color) Option {
+	return func(d *diagram) {
+		d.perspective = c
+	}
+}
+
+// SquareColors changes the light and dark square colours of the theme.
+func SquareColors(light, dark color.Color) Option {
+	return func(d *diagram) {
+		d.theme.Light = light
+		d.theme.Dark = dark
+	}
+}
+
+// BoardTheme sets the square colours, pieces and highlight colours.
+func BoardTheme(t Theme) Option {
+	return func(d *diagram) {
+		d.theme = t
+	}
+}
+
+// Pieces changes the piece set of the theme.
+func Pieces(set PieceSet) Option {
+	return func(d *diagram) {
+		d.theme.Pieces = set
+	}
+}
+
+// CoordinateStyle says where the file and rank labels go.
+type CoordinateStyle int
+
+const (
+	// CoordinatesInside labels the squares along the left and bottom
+	// edges, as image.SVG does. It is the default.
+	CoordinatesInside CoordinateStyle = iota
+	// CoordinatesOutside adds a border around the board for the labels.
+	CoordinatesOutside
+	// NoCoordinates leaves the labels out.
+	NoCoordinates
+)
+
+// Coordinates sets where the file and rank labels are drawn. They follow
+// the perspective of the board.
+func Coordinates(style CoordinateStyle) Option {
+	return func(d *diagram) {
+		d.coordinates = style
+	}
+}
+
+// LastMove highlights the squares m moved from and to.
+func LastMove(m *chess.Move) Option {
+	return func(d *diagram) {
+		if m != nil {
+			d.lastMove = []chess.Square{m.S1(), m.S2()}
+		}
+	}
+}
+
+// CheckHighlight highlights the king of the side to move when it is
+// in check. pos is usually the position whose board is drawn.
+func CheckHighlight(pos *chess.Position) Option {
+	return func(d *diagram) {
+		if sq, ok := checkedKing(pos); ok {
+			d.check = sq
+		}
+	}
+}
+
+// Arrows draws arrows between squares.
+func Arrows(arrows ...Arrow) Option {
+	return func(d *diagram) {
+		d.arrows = append(d.arrows, arrows...)
+	}
+}
+
+// Circles draws circles around squares.
+func Circles(circles ...Circle) Option {
+	return func(d *diagram) {
+		d.circles = append(d.circles, circles...)
+	}
+}
+
+// Drawings draws the arrows and circles of the [%cal] and [%csl]
+// commands attached to m, as written by Lichess and ChessBase. Malformed
+// entries are skipped; use MoveDrawings to see the errors.
+func Drawings(m *chess.Move) Option {
+	return func(d *diagram) {
+		if m == nil {
+			return
+		}
+		arrows, circles, _ := MoveDrawings(m)
+		d.arrows = append(d.arrows, arrows...)
+		d.circles = append(d.circles, circles...)
+	}
+}
+
+type diagram struct {
+	perspective chess.Color
+	theme       Theme
+	coordinates CoordinateStyle
+	lastMove    []chess.Square
+	check       chess.Square
+	arrows      []Arrow
+	circles     []Circle
+}
+
+const (
+	sqSize    = 45 // the square size image.SVG draws with
+	boardSize = 8 * sqSize
+	border    = 18 // the width of the border of CoordinatesOutside
+)
+
+// textElement matches the coordinate labels of image.SVG, its only text.
+var textElement = regexp.MustCompile(`<text [^>]*>[^<]*</text>\n?`)
+
+// SVG writes the board as SVG with the drawings given by opts.
+func SVG(w io.Writer, b *chess.Board, opts ...Option) error {
+	d := &diagram{
+		perspective: chess.White,
+		theme:       Themes["brown"],
+		check:       chess.NoSquare,
+	}
+	for _, op := range opts {
+		op(d)
+	}
+
+	// image.SVG draws the squares, the last move and, for its own piece
+	// set, the pieces; everything else is added to its output.
+	drawn := b
+	if d.theme.Pieces != Cburnett && d.theme.Pieces != "" {
+		drawn = chess.NewBoard(map[chess.Square]chess.Piece{})
+	}
+	marks := d.lastMove
+	if d.theme.Highlight == nil {
+		marks = nil
+	}
+	var buf bytes.Buffer
+	err := image.SVG(&buf, drawn,
+		image.Perspective(d.perspective),
+		image.SquareColors(d.theme.Light, d.theme.Dark),
+		image.MarkSquares(d.theme.Highlight, marks...),
+	)
+	if err != nil {
+		return err
+	}
+	doc := buf.String()
+	// The pieces are nested <svg> elements, so the first opening tag and
+	// the last closing tag are the ones of the document.
+	open := strings.Index(doc, "<svg")
+	openEnd := strings.Index(doc[max(open, 0):], ">") + open + 1
+	end := strings.LastIndex(doc, "</svg>")
+	if open < 0 || openEnd <= open || end < openEnd {
+		return fmt.Errorf("unexpected output from image.SVG")
+	}
+	body := doc[openEnd:end]
+	if d.coordinates != CoordinatesInside {
+		body = textElement.ReplaceAllString(body, "")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(doc[:open])
+	if d.coordinates == CoordinatesOutside {
+		d.writeBorder(&sb)
+	} else {
+		sb.WriteString(doc[open:openEnd])
+	}
+	if d.check != chess.NoSquare && d.theme.Check != nil {
+		if drawn == b {
+			// Put the glow under the king image.SVG drew.
+			body = d.underPiece(body, d.check, d.checkGlow())
+		} else {
+			body += d.checkGlow()
+		}
+	}
+	sb.WriteString(body)
+	if drawn != b {
+		d.writePieces(&sb, b)
+	}
+	d.writeDrawings(&sb)
+	if d.coordinates == CoordinatesOutside {
+		sb.WriteString("</g>\n")
+	}
+	sb.WriteString(doc[end:])
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// writeBorder opens a document with room for the labels around the board
+// and a group for the board itself, which the caller closes.
+func (d *diagram) writeBorder(sb *strings.Builder) {
+	size := boardSize + 2*border
+	fmt.Fprintf(sb, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg">`+"\n", size, size)
+	fmt.Fprintf(sb, `<rect x="0" y="0" width="%d" height="%d" style="%s"/>`+"\n", size, size, paint("fill", d.theme.Dark))
+	style := "text-anchor:middle;dominant-baseline:central;font-size:12px;" + paint("fill", d.theme.Light)
+	for i := range 8 {
+		sq := d.squareAt(i, i)
+		c := border + (float64(i)+0.5)*sqSize
+		for _, x := range []float64{border / 2, border + boardSize + border/2} {
+			fmt.Fprintf(sb, `<text x="%s" y="%s" style="%s">%s</text>`+"\n", num(x), num(c), style, sq.Rank())
+		}
+		for _, y := range []float64{border / 2, border + boardSize + border/2} {
+			fmt.Fprintf(sb, `<text x="%s" y="%s" style="%s">%s</text>`+"\n", num(c), num(y), style, sq.File())
+		}
+	}
+	fmt.Fprintf(sb, `<g transform="translate(%d,%d)">`+"\n", border, border)
+}
+
+// checkGlow returns a radial glow in the check colour over the king's
+// square, fading out towards its edges.
+func (d *diagram) checkGlow() string {
+	x, y := d.center(d.check)
+	style := paint("stop-color", d.theme.Check)
+	return fmt.Sprintf(`<radialGradient id="check-glow">`+
+		`<stop offset="0%%" style="%s"/><stop offset="25%%" style="%s"/>`+
+		`<stop offset="90%%" style="%s;stop-opacity:0"/></radialGradient>`+"\n"+
+		`<rect x="%s" y="%s" width="%d" height="%d" style="fill:url(#check-glow)"/>`+"\n",
+		style, style, style, num(x-sqSize/2.0), num(y-sqSize/2.0), sqSize, sqSize)
+}
+
+// underPiece inserts content into body just before the piece image.SVG
+// drew on sq, which it places by the view box of a nested <svg>.
+func (d *diagram) underPiece(body string, sq chess.Square, content string) string {
+	x, y := d.center(sq)
+	viewBox := fmt.Sprintf(`viewBox="%d %d 360 360"`, -int(x-sqSize/2.0), -int(y-sqSize/2.0))
+	i := strings.Index(body, viewBox)
+	if i < 0 {
+		return body + content
+	}
+	i = strings.LastIndex(body[:i], "<svg")
+	return body[:i] + content + body[i:]
+}
+
+// writePieces draws the pieces of b in the theme's piece set.
+func (d *diagram) writePieces(sb *strings.Builder, b *chess.Board) {
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		if p := b.Piece(sq); p != chess.NoPiece {
+			x, y := d.center(sq)
+			writePiece(sb, d.theme.Pieces, p, x, y)
+		}
+	}
+}
+
+// writeDrawings writes the circles and then the arrows, so arrows
+// starting inside a circle stay visible.
+func (d *diagram) writeDrawings(sb *strings.Builder) {
+	if len(d.arrows)+len(d.circles) == 0 {
+		return
+	}
+	sb.WriteString("<g>\n")
+	for _, c := range d.circles {
+		x, y := d.center(c.Square)
+		width := c.width()
+		fmt.Fprintf(sb, `<circle cx="%s" cy="%s" r="%s" style="fill:none;stroke-width:%s;%s"/>`+"\n",
+			num(x), num(y), num((sqSize-width)/2), num(width), paint("stroke", c.Color))
+	}
+	for _, a := range d.arrows {
+		if a.From == a.To {
+			continue
+		}
+		x1, y1 := d.center(a.From)
+		x2, y2 := d.center(a.To)
+		fmt.Fprintf(sb, `<polygon points="%s" style="%s"/>`+"\n",
+			arrowPoints(x1, y1, x2, y2, a.width()), paint("fill", a.Color))
+	}
+	sb.WriteString("</g>\n")
+}
+
+// squareAt returns the square shown in the given column and row, counted
+// from the top left corner.
+func (d *diagram) squareAt(col, row int) chess.Square {
+	if d.perspective == chess.Black {
+		return chess.NewSquare(chess.File(7-col), chess.Rank(row))
+	}
+	return chess.NewSquare(chess.File(col), chess.Rank(7-row))
+}
+
+// center returns the coordinates of the centre of sq on the board.
+func (d *diagram) center(sq chess.Square) (float64, float64) {
+	col, row := int(sq.File()), 7-int(sq.Rank())
+	if d.perspective == chess.Black {
+		col, row = 7-col, 7-row
+	}
+	return (float64(col) + 0.5) * sqSize, (float64(row) + 0.5) * sqSize
+}
+
+// arrowPoints outlines an arrow from (x1, y1) to (x2, y2) with a shaft of
+// the given width and a head whose tip is on (x2, y2).
+func arrowPoints(x1, y1, x2, y2, width float64) string {
+	length := math.Hypot(x2-x1, y2-y1)
+	dx, dy := (x2-x1)/length, (y2-y1)/length
+	nx, ny := -dy, dx // normal to the arrow
+
+	headLength := math.Min(2.6*width, length/2)
+	headHalf := 1.6 * width
+	bx, by := x2-dx*headLength, y2-dy*headLength
+	half := width / 2
+
+	pts := [][2]float64{
+		{x1 + nx*half, y1 + ny*half},
+		{bx + nx*half, by + ny*half},
+		{bx + nx*headHalf, by + ny*headHalf},
+		{x2, y2},
+		{bx - nx*headHalf, by - ny*headHalf},
+		{bx - nx*half, by - ny*half},
+		{x1 - nx*half, y1 - ny*half},
+	}
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = num(p[0]) + "," + num(p[1])
+	}
+	return strings.Join(parts, " ")
+}
+
+// paint returns the style setting property to c, with the alpha of c as
+// the opacity.
+func paint(property string, c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	style := fmt.Sprintf("%s:#%02x%02x%02x", property, n.R, n.G, n.B)
+	if n.A != 255 {
+		style += fmt.Sprintf(";%s-opacity:%s", property, num(float64(n.A)/255))
+	}
+	return style
+}
+
+// num formats a coordinate with at most two decimals.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
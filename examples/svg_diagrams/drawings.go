@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"regexp"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Arrow is an arrow from one square to another. Arrows starting and
+// ending on the same square are not drawn.
+type Arrow struct {
+	From, To chess.Square
+	Color    color.Color
+	// Width is the width of the shaft in pixels, on squares 45 pixels
+	// wide. Zero means DefaultArrowWidth.
+	Width float64
+}
+
+// Circle is a circle drawn inside a square.
+type Circle struct {
+	Square chess.Square
+	Color  color.Color
+	// Width is the width of the line in pixels. Zero means
+	// DefaultCircleWidth.
+	Width float64
+}
+
+const (
+	DefaultArrowWidth  = 7
+	DefaultCircleWidth = 3.5
+)
+
+func (a Arrow) width() float64 {
+	if a.Width > 0 {
+		return a.Width
+	}
+	return DefaultArrowWidth
+}
+
+func (c Circle) width() float64 {
+	if c.Width > 0 {
+		return c.Width
+	}
+	return DefaultCircleWidth
+}
+
+// DrawingColors maps the colour letters of [%cal] and [%csl] to the
+// colours Lichess draws them with.
+var DrawingColors = map[byte]color.Color{
+	'G': color.NRGBA{21, 120, 27, 204},
+	'R': color.NRGBA{136, 32, 32, 204},
+	'B': color.NRGBA{0, 48, 136, 204},
+	'Y': color.NRGBA{230, 143, 0, 204},
+}
+
+// MoveDrawings returns the arrows and circles of the [%cal] and [%csl]
+// commands attached to m.
+func MoveDrawings(m *chess.Move) ([]Arrow, []Circle, error) {
+	var arrows []Arrow
+	var circles []Circle
+	var errs []string
+	if cal, ok := m.GetCommand("cal"); ok {
+		a, err := ParseArrows(cal)
+		arrows = a
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if csl, ok := m.GetCommand("csl"); ok {
+		c, err := ParseCircles(csl)
+		circles = c
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return arrows, circles, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return arrows, circles, nil
+}
+
+// ParseArrows parses the value of a [%cal] command, a list such as
+// "Ge2e4,Rd8d1". Entries may be separated by commas or spaces. The valid
+// entries are returned even when others are malformed.
+func ParseArrows(s string) ([]Arrow, error) {
+	var arrows []Arrow
+	var bad []string
+	for _, f := range drawingFields(s) {
+		c, ok := DrawingColors[f[0]]
+		if len(f) != 5 || !ok {
+			bad = append(bad, f)
+			continue
+		}
+		from, ok1 := parseSquare(f[1:3])
+		to, ok2 := parseSquare(f[3:5])
+		if !ok1 || !ok2 {
+			bad = append(bad, f)
+			continue
+		}
+		arrows = append(arrows, Arrow{From: from, To: to, Color: c})
+	}
+	return arrows, badEntries("arrow", bad)
+}
+
+// ParseCircles parses the value of a [%csl] command, a list such as
+// "Rd5,Gf7".
+func ParseCircles(s string) ([]Circle, error) {
+	var circles []Circle
+	var bad []string
+	for _, f := range drawingFields(s) {
+		c, ok := DrawingColors[f[0]]
+		if len(f) != 3 || !ok {
+			bad = append(bad, f)
+			continue
+		}
+		sq, ok := parseSquare(f[1:])
+		if !ok {
+			bad = append(bad, f)
+			continue
+		}
+		circles = append(circles, Circle{Square: sq, Color: c})
+	}
+	return circles, badEntries("circle", bad)
+}
+
+func drawingFields(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+func badEntries(kind string, bad []string) error {
+	if len(bad) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid %s %s", kind, strings.Join(bad, ", "))
+}
+
+func parseSquare(s string) (chess.Square, bool) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return chess.NoSquare, false
+	}
+	return chess.NewSquare(chess.File(s[0]-'a'), chess.Rank(s[1]-'1')), true
+}
+
+var drawingCommand = regexp.MustCompile(`\[%(cal|csl)\s+([^\]"]*)\]`)
+
+// NormalizeDrawings rewrites the [%cal] and [%csl] commands of a PGN text
+// so that the chess package keeps all of their entries: its parser only
+// keeps the first of several comma separated command parameters, so
+// "[%cal Ge2e4,Gd2d4]" would lose the second arrow. The entries are
+// separated by spaces instead, which ParseArrows and ParseCircles accept.
+// Quoting the list would also work, but breaks the parsing of any command
+// that follows in the same comment. Call it on PGN text before parsing it.
+func NormalizeDrawings(pgn string) string {
+	return drawingCommand.ReplaceAllStringFunc(pgn, func(cmd string) string {
+		m := drawingCommand.FindStringSubmatch(cmd)
+		return "[%" + m[1] + " " + strings.Join(drawingFields(m[2]), " ") + "]"
+	})
+}
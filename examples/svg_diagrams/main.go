@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -fen "<FEN>" -arrows Ge2e4,Rd8d1 -circles Rd5 -out board.svg
+//	go run . -pgn annotated.pgn -out diagrams/ -theme blue -coords outside
+//
+// With -pgn, every move of the first game carrying [%cal] or [%csl]
+// commands is rendered into the -out directory. Without flags the demo
+// writes a few diagrams into the current directory.
+func main() {
+	fen := flag.String("fen", "", "position to render (default: starting position)")
+	arrows := flag.String("arrows", "", "arrows in [%cal] form, e.g. Ge2e4,Rd8d1")
+	circles := flag.String("circles", "", "circles in [%csl] form, e.g. Rd5,Gf7")
+	pgnPath := flag.String("pgn", "", "annotated PGN file to render the drawings of")
+	outPath := flag.String("out", "", "output .svg file, or directory with -pgn")
+	black := flag.Bool("black", false, "draw the board from Black's side")
+	themeName := flag.String("theme", "brown", "board theme: "+strings.Join(ThemeNames(), ", "))
+	pieces := flag.String("pieces", "", "piece set overriding the theme's: cburnett, unicode or letters")
+	coords := flag.String("coords", "inside", "coordinate labels: inside, outside or none")
+	flag.Parse()
+
+	if *outPath == "" {
+		runDemo()
+		return
+	}
+
+	theme, err := LookupTheme(*themeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *pieces != "" {
+		theme.Pieces = PieceSet(*pieces)
+	}
+	style, err := parseCoordinates(*coords)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := []Option{BoardTheme(theme), Coordinates(style)}
+	if *black {
+		opts = append(opts, Perspective(chess.Black))
+	}
+
+	if *pgnPath != "" {
+		n, err := renderPGN(*pgnPath, *outPath, opts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d diagrams saved to: %s\n", n, *outPath)
+		return
+	}
+
+	pos := chess.StartingPosition()
+	if *fen != "" {
+		opt, err := chess.FEN(*fen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pos = chess.NewGame(opt).Position()
+	}
+	a, err := ParseArrows(*arrows)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := ParseCircles(*circles)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts = append(opts, Arrows(a...), Circles(c...), CheckHighlight(pos))
+	if err := writeSVG(*outPath, pos.Board(), opts...); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Diagram saved to: %s\n", *outPath)
+}
+
+// renderPGN writes a diagram for every move of the first game in the file
+// that has drawings, named after the move, and returns how many it wrote.
+// The move itself and any check are highlighted.
+func renderPGN(pgnPath, dir string, opts ...Option) (int, error) {
+	data, err := os.ReadFile(pgnPath)
+	if err != nil {
+		return 0, err
+	}
+	pgn, err := chess.PGN(strings.NewReader(NormalizeDrawings(string(data))))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, m := range chess.NewGame(pgn).Moves() {
+		arrows, circles, err := MoveDrawings(m)
+		if err != nil {
+			log.Printf("Move %d: %v\n", m.Ply(), err)
+		}
+		if len(arrows)+len(circles) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("ply%03d_%s.svg", m.Ply(), m)
+		path := filepath.Join(dir, name)
+		if err := writeSVG(path, m.Position().Board(), append(opts, Drawings(m), LastMove(m), CheckHighlight(m.Position()))...); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func parseCoordinates(s string) (CoordinateStyle, error) {
+	switch strings.ToLower(s) {
+	case "inside":
+		return CoordinatesInside, nil
+	case "outside":
+		return CoordinatesOutside, nil
+	case "none":
+		return NoCoordinates, nil
+	}
+	return 0, fmt.Errorf("invalid coordinate style %q", s)
+}
+
+func writeSVG(path string, b *chess.Board, opts ...Option) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := SVG(f, b, opts...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runDemo() {
+	fmt.Println("=== SVG Diagrams Example ===")
+
+	// Example 1: Arrows and circles given directly
+	fmt.Println("\n1. Arrows and Circles")
+	game := chess.NewGame()
+	for _, move := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5"} {
+		game.PushMove(move, nil)
+	}
+	path := filepath.Join(".", "ruy_lopez_plans.svg")
+	err := writeSVG(path, game.Position().Board(),
+		Arrows(
+			Arrow{From: chess.B5, To: chess.C6, Color: DrawingColors['R']},
+			Arrow{From: chess.A7, To: chess.A6, Color: DrawingColors['G']},
+			Arrow{From: chess.F3, To: chess.E5, Color: color.RGBA{255, 170, 0, 255}, Width: 4},
+		),
+		Circles(Circle{Square: chess.E5, Color: DrawingColors['R']}),
+	)
+	if err != nil {
+		log.Printf("Error writing SVG: %v\n", err)
+		return
+	}
+	fmt.Printf("Diagram saved to: %s\n", path)
+
+	// Example 2: Parsing [%cal] and [%csl] values
+	fmt.Println("\n2. Parsing Drawing Commands")
+	arrows, err := ParseArrows("Ge2e4,Rd7d5,Xa1a2")
+	fmt.Printf("Parsed %d arrows, error: %v\n", len(arrows), err)
+	circles, _ := ParseCircles("Rd5 Gf7")
+	fmt.Printf("Parsed %d circles\n", len(circles))
+
+	// Example 3: Drawings read from an annotated PGN
+	fmt.Println("\n3. Drawings From an Annotated PGN")
+	fmt.Println("Before:", strings.Split(demoPGN, "\n")[3])
+	fmt.Println("After: ", strings.Split(NormalizeDrawings(demoPGN), "\n")[3])
+	pgn, err := chess.PGN(strings.NewReader(NormalizeDrawings(demoPGN)))
+	if err != nil {
+		log.Printf("Error parsing PGN: %v\n", err)
+		return
+	}
+	for _, m := range chess.NewGame(pgn).Moves() {
+		arrows, circles, _ := MoveDrawings(m)
+		if len(arrows)+len(circles) == 0 {
+			continue
+		}
+		path := filepath.Join(".", fmt.Sprintf("italian_ply%d.svg", m.Ply()))
+		if err := writeSVG(path, m.Position().Board(), Drawings(m)); err != nil {
+			log.Printf("Error writing SVG: %v\n", err)
+			return
+		}
+		fmt.Printf("Move %s: %d arrows, %d circles, saved to: %s\n", m, len(arrows), len(circles), path)
+	}
+
+	// Example 4: Themes, coordinates, last move and check
+	fmt.Println("\n4. Themes and Highlights")
+	game = chess.NewGame()
+	for _, move := range []string{"e4", "e5", "Bc4", "Nc6", "Qh5", "Nf6", "Qxf7#"} {
+		game.PushMove(move, nil)
+	}
+	moves := game.Moves()
+	last := moves[len(moves)-1]
+	styles := []CoordinateStyle{CoordinatesOutside, CoordinatesInside, NoCoordinates, CoordinatesOutside}
+	for i, name := range ThemeNames() {
+		path := filepath.Join(".", "scholars_mate_"+name+".svg")
+		err := writeSVG(path, game.Position().Board(),
+			BoardTheme(Themes[name]),
+			Coordinates(styles[i%len(styles)]),
+			Perspective(chess.Black),
+			LastMove(last),
+			CheckHighlight(game.Position()),
+		)
+		if err != nil {
+			log.Printf("Error writing SVG: %v\n", err)
+			return
+		}
+		fmt.Printf("Theme %-6s pieces %-9s saved to: %s\n", name, Themes[name].Pieces, path)
+	}
+}
+
+const demoPGN = `[Event "Drawings"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 { [%cal Gc4f7,Gf3g5] [%csl Rf7] } Bc5
+4. c3 { [%cal Gd2d4,Yc3d4] } Nf6 { [%csl Re4] } *`
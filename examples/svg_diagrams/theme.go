@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// PieceSet names a way of drawing the pieces.
+type PieceSet string
+
+const (
+	// Cburnett are the pieces image.SVG draws.
+	Cburnett PieceSet = "cburnett"
+	// UnicodePieces draws the chess symbols of Unicode, which needs no
+	// artwork but depends on the fonts of the viewer.
+	UnicodePieces PieceSet = "unicode"
+	// LetterPieces draws discs marked with the piece letter, as in
+	// diagrams meant to be printed small.
+	LetterPieces PieceSet = "letters"
+)
+
+// Theme is a look for the board, selected with BoardTheme.
+type Theme struct {
+	Light, Dark color.Color
+	Pieces      PieceSet
+	// Highlight marks the squares of the last move, Check the king in
+	// check.
+	Highlight color.Color
+	Check     color.Color
+}
+
+// Themes are the named themes. Brown is the default, with the colours of
+// image.SVG.
+var Themes = map[string]Theme{
+	"brown": {
+		Light: color.RGBA{235, 209, 166, 255}, Dark: color.RGBA{165, 117, 81, 255},
+		Pieces: Cburnett, Highlight: color.RGBA{255, 255, 0, 255}, Check: color.RGBA{255, 0, 0, 255},
+	},
+	"blue": {
+		Light: color.RGBA{222, 227, 230, 255}, Dark: color.RGBA{140, 162, 173, 255},
+		Pieces: Cburnett, Highlight: color.RGBA{155, 199, 0, 255}, Check: color.RGBA{255, 0, 0, 255},
+	},
+	"green": {
+		Light: color.RGBA{238, 238, 210, 255}, Dark: color.RGBA{118, 150, 86, 255},
+		Pieces: UnicodePieces, Highlight: color.RGBA{255, 255, 0, 255}, Check: color.RGBA{255, 0, 0, 255},
+	},
+	"print": {
+		Light: color.RGBA{255, 255, 255, 255}, Dark: color.RGBA{190, 190, 190, 255},
+		Pieces: LetterPieces, Highlight: color.RGBA{0, 0, 0, 255}, Check: color.RGBA{120, 120, 120, 255},
+	},
+}
+
+// LookupTheme returns the theme with the given name.
+func LookupTheme(name string) (Theme, error) {
+	t, ok := Themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, have %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// ThemeNames returns the names of the themes in alphabetical order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var pieceSymbols = map[chess.PieceType]string{
+	chess.King:   "♚",
+	chess.Queen:  "♛",
+	chess.Rook:   "♜",
+	chess.Bishop: "♝",
+	chess.Knight: "♞",
+	chess.Pawn:   "♟",
+}
+
+// writePiece draws p centred on (x, y) in the given set. Cburnett pieces
+// are drawn by image.SVG and are not handled here.
+func writePiece(sb *strings.Builder, set PieceSet, p chess.Piece, x, y float64) {
+	fill, ink := "#ffffff", "#000000"
+	if p.Color() == chess.Black {
+		fill, ink = "#000000", "#ffffff"
+	}
+	switch set {
+	case UnicodePieces:
+		// The black symbols are used for both sides and filled, as the
+		// outlined white ones show the square through them. U+FE0E asks
+		// for the text rather than the emoji form of the pawn.
+		fmt.Fprintf(sb, `<text x="%s" y="%s" style="text-anchor:middle;dominant-baseline:central;font-size:38px;fill:%s;stroke:#000000;stroke-width:1">%s&#xfe0e;</text>`+"\n",
+			num(x), num(y+2), fill, pieceSymbols[p.Type()])
+	case LetterPieces:
+		fmt.Fprintf(sb, `<circle cx="%s" cy="%s" r="17" style="fill:%s;stroke:#000000;stroke-width:1.5"/>`+"\n",
+			num(x), num(y), fill)
+		fmt.Fprintf(sb, `<text x="%s" y="%s" style="text-anchor:middle;dominant-baseline:central;font-family:sans-serif;font-weight:bold;font-size:20px;fill:%s">%s</text>`+"\n",
+			num(x), num(y+1), ink, strings.ToUpper(p.Type().String()))
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import "github.com/corentings/chess/v2"
+
+// checkedKing returns the square of the king of the side to move if it is
+// in check. The chess package keeps whether a position is in check to
+// itself, so unless last, the move that led to pos, is tagged as a check,
+// the attacks on the king are looked for here.
+func checkedKing(pos *chess.Position, last *chess.Move) (chess.Square, bool) {
+	b := pos.Board()
+	king := chess.NewPiece(chess.King, pos.Turn())
+	for sq, p := range b.SquareMap() {
+		if p == king {
+			if last != nil && last.HasTag(chess.Check) {
+				return sq, true
+			}
+			return sq, attacked(b, sq, pos.Turn().Other())
+		}
+	}
+	return chess.NoSquare, false
+}
+
+// attacked reports whether a piece of color by attacks sq.
+func attacked(b *chess.Board, sq chess.Square, by chess.Color) bool {
+	file, rank := int(sq.File()), int(sq.Rank())
+	pieceAt := func(df, dr int) chess.Piece {
+		f, r := file+df, rank+dr
+		if f < 0 || f > 7 || r < 0 || r > 7 {
+			return chess.NoPiece
+		}
+		return b.Piece(chess.NewSquare(chess.File(f), chess.Rank(r)))
+	}
+	is := func(p chess.Piece, types ...chess.PieceType) bool {
+		if p == chess.NoPiece || p.Color() != by {
+			return false
+		}
+		for _, t := range types {
+			if p.Type() == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Pawns attack towards the other side, so look back towards theirs.
+	pawnRank := -1
+	if by == chess.Black {
+		pawnRank = 1
+	}
+	if is(pieceAt(-1, pawnRank), chess.Pawn) || is(pieceAt(1, pawnRank), chess.Pawn) {
+		return true
+	}
+	for _, d := range [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}} {
+		if is(pieceAt(d[0], d[1]), chess.Knight) {
+			return true
+		}
+	}
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		if is(pieceAt(d[0], d[1]), chess.King) {
+			return true
+		}
+		slider := chess.Rook
+		if d[0] != 0 && d[1] != 0 {
+			slider = chess.Bishop
+		}
+		for i := 1; i < 8; i++ {
+			f, r := file+i*d[0], rank+i*d[1]
+			if f < 0 || f > 7 || r < 0 || r > 7 {
+				break
+			}
+			p := pieceAt(i*d[0], i*d[1])
+			if p == chess.NoPiece {
+				continue
+			}
+			if is(p, slider, chess.Queen) {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
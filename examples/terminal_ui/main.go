@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Usage:
+//
+//	go run . -fen "<FEN>" -black
+//	go run . -moves "e4 e5 Qh5 Nc6 Bc4 Nf6 Qxf7#" -mode ansi
+//	go run . -pgn games.pgn
+//
+// -mode is auto, ansi, unicode or ascii; auto draws coloured squares on a
+// terminal and plain ASCII when the output is redirected. -pgn browses the
+// games of a file interactively: ←/→ step through the moves, ↑/↓ switch
+// between variations, n/p change game and q or Esc quits. Without flags
+// the demo prints a few boards.
+func main() {
+	fen := flag.String("fen", "", "starting position (default: standard)")
+	moves := flag.String("moves", "", "space separated SAN moves to play from the position")
+	black := flag.Bool("black", false, "draw the board from Black's side")
+	modeName := flag.String("mode", "auto", "auto, ansi, unicode or ascii")
+	pgnPath := flag.String("pgn", "", "PGN file to browse interactively")
+	flag.Parse()
+
+	if *pgnPath == "" && *fen == "" && *moves == "" {
+		runDemo()
+		return
+	}
+
+	mode, err := parseMode(*modeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *pgnPath != "" {
+		games, err := readGames(*pgnPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := Run(NewNavigator(games), mode); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	game, err := playMoves(*fen, strings.Fields(*moves))
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := []Option{WithMode(mode), lastMove(game)}
+	if *black {
+		opts = append(opts, Perspective(chess.Black))
+	}
+	fmt.Print(Render(game.Position(), opts...))
+}
+
+func parseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "auto":
+		return DetectMode(os.Stdout), nil
+	case "ansi":
+		return ModeANSI, nil
+	case "unicode":
+		return ModeUnicode, nil
+	case "ascii":
+		return ModeASCII, nil
+	}
+	return 0, fmt.Errorf("invalid mode %q", s)
+}
+
+// playMoves plays the SAN moves from fen, or from the standard position
+// when fen is empty.
+func playMoves(fen string, moves []string) (*chess.Game, error) {
+	game := chess.NewGame()
+	if fen != "" {
+		opt, err := chess.FEN(fen)
+		if err != nil {
+			return nil, err
+		}
+		game = chess.NewGame(opt)
+	}
+	for _, move := range moves {
+		if err := game.PushMove(move, nil); err != nil {
+			return nil, fmt.Errorf("move %s: %w", move, err)
+		}
+	}
+	return game, nil
+}
+
+// readGames returns the games of a PGN file, skipping those that do not
+// parse.
+func readGames(path string) ([]*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return scanGames(f)
+}
+
+func scanGames(r io.Reader) ([]*chess.Game, error) {
+	var games []*chess.Game
+	scanner := chess.NewScanner(r)
+	for i := 1; scanner.HasNext(); i++ {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			log.Printf("Skipping game %d: %v\n", i, err)
+			continue
+		}
+		games = append(games, game)
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no games found")
+	}
+	return games, nil
+}
+
+// lastMove highlights the last move of the main line of game, if any.
+func lastMove(game *chess.Game) Option {
+	moves := game.Moves()
+	if len(moves) == 0 {
+		return func(*renderer) {}
+	}
+	return LastMove(moves[len(moves)-1])
+}
+
+func runDemo() {
+	fmt.Println("=== Terminal UI Example ===")
+
+	game, err := playMoves("", []string{"e4", "e5", "Nf3", "Nc6", "Bb5"})
+	if err != nil {
+		log.Printf("Error playing moves: %v\n", err)
+		return
+	}
+
+	// Example 1: The mode chosen for this output
+	fmt.Println("\n1. Automatic Mode")
+	fmt.Println("The position with Board.Draw:")
+	fmt.Println(game.Position().Board().Draw())
+	fmt.Println("The same position with Render:")
+	fmt.Print(Render(game.Position(), lastMove(game)))
+
+	// Example 2: Unicode glyphs from Black's side
+	fmt.Println("\n2. Unicode From Black's Perspective")
+	fmt.Print(Render(game.Position(), WithMode(ModeUnicode), Perspective(chess.Black), lastMove(game)))
+
+	// Example 3: Check highlighting
+	fmt.Println("\n3. Check and Last Move in ASCII")
+	mate, err := playMoves("", []string{"e4", "e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#"})
+	if err != nil {
+		log.Printf("Error playing moves: %v\n", err)
+		return
+	}
+	fmt.Print(Render(mate.Position(), WithMode(ModeASCII), lastMove(mate)))
+	fmt.Println("[ ] marks the last move, < > the king in check")
+
+	// Example 4: Coloured squares
+	fmt.Println("\n4. ANSI Colours")
+	fmt.Print(Render(mate.Position(), WithMode(ModeANSI), lastMove(mate)))
+
+	// Example 5: The navigator driven by a fixed sequence of keys instead
+	// of the keyboard
+	fmt.Println("\n5. Game Navigator")
+	games, err := scanGames(strings.NewReader(demoPGN))
+	if err != nil {
+		log.Printf("Error reading games: %v\n", err)
+		return
+	}
+	nav := NewNavigator(games)
+	steps := []struct {
+		keys  []Key
+		label string
+	}{
+		{[]Key{KeyForward, KeyForward, KeyForward, KeyForward, KeyForward}, "five moves forward"},
+		{[]Key{KeyNextVariation}, "next variation"},
+		{[]Key{KeyBack, KeyBack, KeyMainLine, KeyEnd}, "back to the main line, then to the end"},
+		{[]Key{KeyNextGame, KeyForward, KeyForward, KeyForward, KeyFlip}, "next game, three moves, flipped"},
+	}
+	for _, step := range steps {
+		for _, k := range step.keys {
+			nav.Handle(k)
+		}
+		fmt.Printf("\n-- %s --\n", step.label)
+		fmt.Print(nav.View(ModeASCII))
+	}
+}
+
+const demoPGN = `[Event "Casual game"]
+[White "Anderssen"]
+[Black "Dufresne"]
+[Result "*"]
+
+{ Anderssen playing the Evans Gambit. } 1. e4 e5 2. Nf3 Nc6 3. Bc4 { The Italian. }
+(3. Bb5 { The Spanish. } a6) (3. d4 exd4) 3... Bc5 4. b4 $5 { The Evans Gambit. }
+Bxb4 5. c3 Ba5 *
+
+[Event "Paris"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 $2 { Developing the bishop, but it will be
+exchanged for nothing. } 4. dxe5 Bxf3 5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 1-0`
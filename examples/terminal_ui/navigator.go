@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Key is a key the navigator reacts to.
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyForward
+	KeyBack
+	KeyNextVariation
+	KeyPrevVariation
+	KeyMainLine
+	KeyStart
+	KeyEnd
+	KeyNextGame
+	KeyPrevGame
+	KeyFlip
+	KeyQuit
+)
+
+// Navigator browses the games of a PGN file one position at a time. It
+// moves through a game with the game's own navigation (GoForward, GoBack,
+// NavigateToMainLine) and keeps the current move alongside, as the game
+// does not expose it.
+type Navigator struct {
+	games       []*chess.Game
+	titles      []string
+	index       int
+	game        *chess.Game
+	current     *chess.Move
+	perspective chess.Color
+}
+
+// NewNavigator returns a navigator at the start of the first game.
+func NewNavigator(games []*chess.Game) *Navigator {
+	n := &Navigator{games: games, perspective: chess.White}
+	for i, g := range games {
+		n.titles = append(n.titles, gameTitle(i, g))
+	}
+	n.selectGame(0)
+	return n
+}
+
+func gameTitle(i int, g *chess.Game) string {
+	white, black := g.GetTagPair("White"), g.GetTagPair("Black")
+	if white == "" && black == "" {
+		return fmt.Sprintf("%d. Game %d", i+1, i+1)
+	}
+	return fmt.Sprintf("%d. %s – %s (%s)", i+1, white, black, g.Outcome())
+}
+
+// selectGame switches to game i and rewinds it to the starting position.
+func (n *Navigator) selectGame(i int) {
+	if i < 0 || i >= len(n.games) {
+		return
+	}
+	n.index = i
+	n.game = n.games[i]
+	// A parsed game is left at its last move.
+	for n.game.GoBack() {
+	}
+	n.current = n.game.GetRootMove()
+}
+
+// Handle applies k and reports whether the navigator should keep running.
+func (n *Navigator) Handle(k Key) bool {
+	switch k {
+	case KeyForward:
+		if n.game.GoForward() {
+			n.current = n.current.Children()[0]
+		}
+	case KeyBack:
+		if n.game.GoBack() {
+			n.current = n.current.Parent()
+		}
+	case KeyNextVariation:
+		n.switchVariation(1)
+	case KeyPrevVariation:
+		n.switchVariation(-1)
+	case KeyMainLine:
+		n.game.NavigateToMainLine()
+		if moves := n.game.Moves(); len(moves) > 0 {
+			n.current = moves[0]
+		}
+	case KeyStart:
+		for n.game.GoBack() {
+			n.current = n.current.Parent()
+		}
+	case KeyEnd:
+		for n.game.GoForward() {
+			n.current = n.current.Children()[0]
+		}
+	case KeyNextGame:
+		n.selectGame(n.index + 1)
+	case KeyPrevGame:
+		n.selectGame(n.index - 1)
+	case KeyFlip:
+		n.perspective = n.perspective.Other()
+	case KeyQuit:
+		return false
+	}
+	return true
+}
+
+// switchVariation replaces the current move by the sibling step places
+// away, wrapping around: the main move followed by Game.Variations.
+func (n *Navigator) switchVariation(step int) {
+	parent := n.current.Parent()
+	if parent == nil {
+		return
+	}
+	siblings := append([]*chess.Move{parent.Children()[0]}, n.game.Variations(parent)...)
+	if len(siblings) < 2 {
+		return
+	}
+	i := indexOf(siblings, n.current)
+	next := siblings[(i+step+len(siblings))%len(siblings)]
+	// Playing a move that is already in the tree moves onto it without
+	// changing the tree.
+	if !n.game.GoBack() {
+		return
+	}
+	if err := n.game.Move(next, nil); err != nil {
+		n.game.GoForward()
+		return
+	}
+	n.current = next
+}
+
+func indexOf(moves []*chess.Move, m *chess.Move) int {
+	for i, c := range moves {
+		if c == m {
+			return i
+		}
+	}
+	return 0
+}
+
+// View draws the board with the move information below it and the list
+// of games on its right.
+func (n *Navigator) View(mode Mode) string {
+	opts := []Option{WithMode(mode), Perspective(n.perspective)}
+	if n.current.Parent() != nil {
+		opts = append(opts, LastMove(n.current))
+	}
+	board := strings.Split(strings.TrimRight(Render(n.game.CurrentPosition(), opts...), "\n"), "\n")
+
+	// The board is 26 columns wide whatever the mode; the escape codes
+	// of ModeANSI take no room.
+	list := n.gameList(len(board))
+	var sb strings.Builder
+	for i, line := range board {
+		if i == len(board)-1 {
+			line += " " // the file labels are one column short
+		}
+		sb.WriteString(line + "   " + list[i] + "\n")
+	}
+	sb.WriteString("\n")
+	for _, line := range n.info() {
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// gameList returns rows lines of the game list, scrolled so the current
+// game is visible.
+func (n *Navigator) gameList(rows int) []string {
+	lines := make([]string, rows)
+	first := max(0, min(n.index-rows/2, len(n.titles)-rows))
+	for i := range rows {
+		g := first + i
+		if g >= len(n.titles) {
+			break
+		}
+		marker := "  "
+		if g == n.index {
+			marker = "> "
+		}
+		lines[i] = marker + truncate(n.titles[g], 40)
+	}
+	return lines
+}
+
+// info describes the current move: its number and SAN, the line it is
+// on, the moves that follow and its comment.
+func (n *Navigator) info() []string {
+	var lines []string
+	parent := n.current.Parent()
+	if parent == nil {
+		lines = append(lines, "Starting position")
+	} else {
+		lines = append(lines, "Move: "+moveLabel(n.current))
+		if siblings := parent.Children(); len(siblings) > 1 {
+			var alts []string
+			for _, s := range siblings {
+				label := san(s)
+				if s == n.current {
+					label = "[" + label + "]"
+				}
+				alts = append(alts, label)
+			}
+			lines = append(lines, "Alternatives: "+strings.Join(alts, " "))
+		}
+	}
+	if children := n.current.Children(); len(children) > 0 {
+		next := "Next: " + san(children[0])
+		if len(children) > 1 {
+			var alts []string
+			for _, c := range children[1:] {
+				alts = append(alts, san(c))
+			}
+			next += " (also " + strings.Join(alts, ", ") + ")"
+		}
+		lines = append(lines, next)
+	} else {
+		lines = append(lines, "End of line, result "+n.game.Outcome().String())
+	}
+	if c := strings.TrimSpace(n.current.Comments()); c != "" {
+		lines = append(lines, "")
+		lines = append(lines, wrap(c, 60)...)
+	}
+	return lines
+}
+
+// moveLabel returns the move with its number and NAG, e.g. "12... Nf3!".
+func moveLabel(m *chess.Move) string {
+	before := m.Parent().Position()
+	number := "1"
+	if fields := strings.Fields(before.String()); len(fields) >= 6 {
+		number = fields[5]
+	}
+	dots := ". "
+	if before.Turn() == chess.Black {
+		dots = "... "
+	}
+	return number + dots + san(m) + nagText(m.NAG())
+}
+
+func san(m *chess.Move) string {
+	return chess.AlgebraicNotation{}.Encode(m.Parent().Position(), m)
+}
+
+var nagSymbols = map[string]string{"$1": "!", "$2": "?", "$3": "!!", "$4": "??", "$5": "!?", "$6": "?!"}
+
+func nagText(nag string) string {
+	if s, ok := nagSymbols[nag]; ok {
+		return s
+	}
+	if nag != "" {
+		return " " + nag
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// wrap breaks s into lines of at most width columns at spaces.
+func wrap(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
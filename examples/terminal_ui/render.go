@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/corentings/chess/v2"
+)
+
+// Mode is how a board is drawn in the terminal.
+type Mode int
+
+const (
+	// ModeASCII uses letters for the pieces and no escape codes, for
+	// logs, pipes and terminals without Unicode.
+	ModeASCII Mode = iota
+	// ModeUnicode uses chess glyphs but no colours, e.g. when NO_COLOR
+	// is set.
+	ModeUnicode
+	// ModeANSI draws glyphs on coloured squares using 24-bit ANSI colours.
+	ModeANSI
+)
+
+// DetectMode picks the mode for f: ANSI for a terminal, ASCII for
+// anything else, such as a file or a pipe. NO_COLOR and TERM=dumb turn the
+// colours off.
+func DetectMode(f *os.File) Mode {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ModeASCII
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return ModeASCII
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return ModeUnicode
+	}
+	return ModeANSI
+}
+
+// Option customises a rendered board.
+type Option func(*renderer)
+
+// Perspective draws the board from the given side. White is the default.
+func Perspective(c chess.Color) Option {
+	return func(r *renderer) {
+		r.perspective = c
+	}
+}
+
+// WithMode sets how the board is drawn. The default is DetectMode for
+// standard output.
+func WithMode(m Mode) Option {
+	return func(r *renderer) {
+		r.mode = m
+	}
+}
+
+// LastMove highlights the squares m moved from and to. m should be the
+// move that led to the rendered position; when it is tagged as a check the
+// king is highlighted without looking for the attacks on it.
+func LastMove(m *chess.Move) Option {
+	return func(r *renderer) {
+		if m != nil {
+			r.lastMove = []chess.Square{m.S1(), m.S2()}
+			r.last = m
+		}
+	}
+}
+
+type renderer struct {
+	perspective chess.Color
+	mode        Mode
+	lastMove    []chess.Square
+	last        *chess.Move
+	check       chess.Square
+}
+
+// rgb is a colour of the ANSI mode.
+type rgb struct{ r, g, b uint8 }
+
+// The square colours are those of image.SVG; highlighted squares are
+// tinted the way its MarkSquares does.
+var (
+	lightSquare     = rgb{235, 209, 166}
+	darkSquare      = rgb{165, 117, 81}
+	lastMoveTint    = rgb{255, 255, 0}
+	checkTint       = rgb{255, 0, 0}
+	whitePieceColor = rgb{255, 255, 255}
+	blackPieceColor = rgb{0, 0, 0}
+)
+
+const ansiReset = "\x1b[0m"
+
+// Render draws pos as text, one line per rank followed by the files. The
+// king of the side to move is highlighted when in check.
+func Render(pos *chess.Position, opts ...Option) string {
+	r := &renderer{
+		perspective: chess.White,
+		mode:        DetectMode(os.Stdout),
+		check:       chess.NoSquare,
+	}
+	for _, op := range opts {
+		op(r)
+	}
+	if sq, ok := checkedKing(pos, r.last); ok {
+		r.check = sq
+	}
+
+	b := pos.Board()
+	var sb strings.Builder
+	for row := range 8 {
+		rank := r.squareAt(0, row).Rank()
+		fmt.Fprintf(&sb, "%s ", rank)
+		for col := range 8 {
+			sq := r.squareAt(col, row)
+			sb.WriteString(r.cell(sq, b.Piece(sq)))
+		}
+		if r.mode == ModeANSI {
+			sb.WriteString(ansiReset)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" ")
+	for col := range 8 {
+		fmt.Fprintf(&sb, " %s ", r.squareAt(col, 0).File())
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// cell returns the three columns drawn for a square.
+func (r *renderer) cell(sq chess.Square, p chess.Piece) string {
+	marked := r.isLastMove(sq)
+	switch r.mode {
+	case ModeANSI:
+		bg := darkSquare
+		if (int(sq.File())+int(sq.Rank()))%2 == 1 {
+			bg = lightSquare
+		}
+		switch {
+		case sq == r.check:
+			bg = tint(bg, checkTint, 0.6)
+		case marked:
+			bg = tint(bg, lastMoveTint, 0.4)
+		}
+		glyph := " "
+		fg := whitePieceColor
+		if p != chess.NoPiece {
+			// The filled glyphs are used for both sides so the colour of
+			// the piece is the foreground colour.
+			glyph = chess.NewPiece(p.Type(), chess.Black).String()
+			if p.Color() == chess.Black {
+				fg = blackPieceColor
+			}
+		}
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm\x1b[38;2;%d;%d;%dm %s ", bg.r, bg.g, bg.b, fg.r, fg.g, fg.b, glyph)
+	case ModeUnicode:
+		glyph := "·"
+		if p != chess.NoPiece {
+			glyph = p.String()
+		}
+		return r.brackets(sq, marked, glyph)
+	default:
+		glyph := "."
+		if p != chess.NoPiece {
+			glyph = pieceLetter(p)
+		}
+		return r.brackets(sq, marked, glyph)
+	}
+}
+
+// brackets surrounds the glyph of a highlighted square, as the modes
+// without colour cannot tint it: [ ] for the last move, < > for a king in
+// check.
+func (r *renderer) brackets(sq chess.Square, marked bool, glyph string) string {
+	switch {
+	case sq == r.check:
+		return "<" + glyph + ">"
+	case marked:
+		return "[" + glyph + "]"
+	}
+	return " " + glyph + " "
+}
+
+func (r *renderer) isLastMove(sq chess.Square) bool {
+	for _, s := range r.lastMove {
+		if s == sq {
+			return true
+		}
+	}
+	return false
+}
+
+// squareAt returns the square shown in the given column and row, counted
+// from the top left corner.
+func (r *renderer) squareAt(col, row int) chess.Square {
+	if r.perspective == chess.Black {
+		return chess.NewSquare(chess.File(7-col), chess.Rank(row))
+	}
+	return chess.NewSquare(chess.File(col), chess.Rank(7-row))
+}
+
+// pieceLetter returns the FEN letter of p: upper case for White.
+func pieceLetter(p chess.Piece) string {
+	letter := p.Type().String()
+	if p.Color() == chess.White {
+		return strings.ToUpper(letter)
+	}
+	return letter
+}
+
+// tint lays c over base with the given opacity.
+func tint(base, c rgb, opacity float64) rgb {
+	mix := func(a, b uint8) uint8 { return uint8(float64(a)*(1-opacity) + float64(b)*opacity) }
+	return rgb{mix(base.r, c.r), mix(base.g, c.g), mix(base.b, c.b)}
+}
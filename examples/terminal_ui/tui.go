@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Run shows the navigator full screen, drawn in mode, and handles keys
+// until q or Esc is pressed. Standard input must be a terminal; raw mode
+// is set with stty, so Run works on Linux and macOS but not in the Windows
+// console.
+func Run(n *Navigator, mode Mode) error {
+	if DetectMode(os.Stdin) == ModeASCII {
+		return errors.New("standard input is not a terminal")
+	}
+	restore, err := rawMode()
+	if err != nil {
+		return fmt.Errorf("cannot switch the terminal to raw mode: %w", err)
+	}
+	defer restore()
+
+	// Use the alternate screen and hide the cursor while browsing.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		// Raw mode turns off the translation of \n into \r\n.
+		screen := n.View(mode) + "\n" + helpLine
+		fmt.Print("\x1b[H\x1b[2J" + strings.ReplaceAll(screen, "\n", "\r\n"))
+		k, err := readKey(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !n.Handle(k) {
+			return nil
+		}
+	}
+}
+
+const helpLine = "←/→ move  ↑/↓ variation  m main line  Home/End start/end  n/p game  f flip  q/Esc quit\n"
+
+// rawMode puts the terminal in raw mode and returns a function restoring
+// the previous settings.
+func rawMode() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(state)) }, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// readKey reads one key press, decoding the escape sequences of the arrow,
+// Home and End keys.
+func readKey(in *bufio.Reader) (Key, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return KeyNone, err
+	}
+	switch b {
+	case 'l', ' ':
+		return KeyForward, nil
+	case 'h', 127: // backspace
+		return KeyBack, nil
+	case 'j':
+		return KeyNextVariation, nil
+	case 'k':
+		return KeyPrevVariation, nil
+	case 'm':
+		return KeyMainLine, nil
+	case 'g':
+		return KeyStart, nil
+	case 'G':
+		return KeyEnd, nil
+	case 'n':
+		return KeyNextGame, nil
+	case 'p':
+		return KeyPrevGame, nil
+	case 'f':
+		return KeyFlip, nil
+	case 'q', 3: // Ctrl-C, which raw mode delivers as a byte
+		return KeyQuit, nil
+	case 0x1b:
+		return readEscape(in)
+	}
+	return KeyNone, nil
+}
+
+// readEscape decodes the rest of an escape sequence: ESC [ A to D for the
+// arrows, ESC [ H / ESC [ F or ESC [ 1 ~ / ESC [ 4 ~ for Home and End.
+// A terminal writes a whole sequence at once, so its bytes are already
+// buffered when the ESC is read. An ESC with nothing after it is the Esc
+// key, which quits; waiting for more input would block until the next key.
+func readEscape(in *bufio.Reader) (Key, error) {
+	if in.Buffered() == 0 {
+		return KeyQuit, nil
+	}
+	b, ok := readBuffered(in)
+	if !ok || (b != '[' && b != 'O') {
+		return KeyNone, nil
+	}
+	b, _ = readBuffered(in)
+	switch b {
+	case 'A':
+		return KeyPrevVariation, nil
+	case 'B':
+		return KeyNextVariation, nil
+	case 'C':
+		return KeyForward, nil
+	case 'D':
+		return KeyBack, nil
+	case 'H':
+		return KeyStart, nil
+	case 'F':
+		return KeyEnd, nil
+	case '1', '7':
+		readBuffered(in) // the closing ~
+		return KeyStart, nil
+	case '4', '8':
+		readBuffered(in)
+		return KeyEnd, nil
+	case '5':
+		readBuffered(in) // Page Up
+		return KeyPrevGame, nil
+	case '6':
+		readBuffered(in) // Page Down
+		return KeyNextGame, nil
+	}
+	return KeyNone, nil
+}
+
+// readBuffered reads a byte of an escape sequence without waiting for
+// input, so that a truncated sequence cannot block.
+func readBuffered(in *bufio.Reader) (byte, bool) {
+	if in.Buffered() == 0 {
+		return 0, false
+	}
+	b, err := in.ReadByte()
+	return b, err == nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+// Score is an engine evaluation. Like UCI itself it is given from the
+// point of view of the side to move in the analysed position.
+type Score struct {
+	CP         int  // centipawns, when Mate is 0
+	Mate       int  // mate in N moves; negative when the side to move gets mated
+	LowerBound bool // the real score is at least this
+	UpperBound bool // the real score is at most this
+}
+
+// IsMate reports whether the score is a forced mate.
+func (s Score) IsMate() bool {
+	return s.Mate != 0
+}
+
+// ForWhite returns the score from White's point of view, given the side to
+// move in the analysed position.
+func (s Score) ForWhite(turn chess.Color) Score {
+	if turn == chess.Black {
+		s.CP, s.Mate = -s.CP, -s.Mate
+		s.LowerBound, s.UpperBound = s.UpperBound, s.LowerBound
+	}
+	return s
+}
+
+func (s Score) String() string {
+	if s.IsMate() {
+		return fmt.Sprintf("#%d", s.Mate)
+	}
+	return fmt.Sprintf("%+.2f", float64(s.CP)/100)
+}
+
+// Line is one principal variation reported by the engine.
+type Line struct {
+	MultiPV  int // 1 for the best line
+	Depth    int
+	SelDepth int
+	Score    Score
+	Nodes    int
+	NPS      int
+	Time     time.Duration
+	// PV holds the variation as legal moves, replayed from the analysed
+	// position so the moves carry their tags (captures, checks, castling).
+	PV []*chess.Move
+	// Truncated is set when the engine's PV contained a move that is not
+	// legal; PV then stops before that move.
+	Truncated bool
+}
+
+// SAN returns the PV in algebraic notation starting from pos.
+func (l Line) SAN(pos *chess.Position) []string {
+	sans := make([]string, 0, len(l.PV))
+	for _, m := range l.PV {
+		sans = append(sans, chess.AlgebraicNotation{}.Encode(pos, m))
+		pos = pos.Update(m)
+	}
+	return sans
+}
+
+// Result is the outcome of a finished search.
+type Result struct {
+	BestMove *chess.Move
+	Ponder   *chess.Move
+	Lines    []Line // indexed by MultiPV-1
+}
+
+// Best returns the best line, or false if the engine reported none.
+func (r *Result) Best() (Line, bool) {
+	if len(r.Lines) == 0 {
+		return Line{}, false
+	}
+	return r.Lines[0], true
+}
+
+// Analyzer runs searches on a UCI engine and returns typed results. The
+// uci package only keeps the last info line of a search, so the Analyzer
+// also listens to the engine's debug log to pick up every info line as it
+// arrives; that is what makes streaming during infinite analysis possible.
+type Analyzer struct {
+	engine *uci.Engine
+
+	mu      sync.Mutex
+	pos     *chess.Position
+	lines   map[int]Line
+	updates chan Line
+	done    chan error
+}
+
+// NewAnalyzer starts the engine at path and sends the uci handshake.
+func NewAnalyzer(path string) (*Analyzer, error) {
+	a := &Analyzer{lines: map[int]Line{}}
+	engine, err := uci.New(path, uci.Debug, uci.Logger(log.New(a, "", 0)))
+	if err != nil {
+		return nil, err
+	}
+	a.engine = engine
+	if err := engine.Run(uci.CmdUCI, uci.CmdIsReady); err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Engine returns the underlying engine, e.g. to send options.
+func (a *Analyzer) Engine() *uci.Engine {
+	return a.engine
+}
+
+// Close stops the engine.
+func (a *Analyzer) Close() error {
+	return a.engine.Close()
+}
+
+// Analyze searches pos with the given limits and returns multiPV lines.
+func (a *Analyzer) Analyze(pos *chess.Position, limits uci.CmdGo, multiPV int) (*Result, error) {
+	if limits.Infinite {
+		return nil, errors.New("use Start for infinite analysis")
+	}
+	if err := a.prepare(pos, multiPV); err != nil {
+		return nil, err
+	}
+	if err := a.engine.Run(limits); err != nil {
+		return nil, err
+	}
+	return a.result(pos)
+}
+
+// Start begins infinite analysis of pos. Every info line carrying a PV is
+// sent on the returned channel as it arrives; updates are dropped when the
+// channel's buffer is full. The channel is closed once Stop ends the
+// search.
+func (a *Analyzer) Start(pos *chess.Position, multiPV int) (<-chan Line, error) {
+	if err := a.prepare(pos, multiPV); err != nil {
+		return nil, err
+	}
+
+	updates := make(chan Line, 16)
+	done := make(chan error, 1)
+	a.mu.Lock()
+	a.updates = updates
+	a.done = done
+	a.mu.Unlock()
+
+	go func() {
+		err := a.engine.Run(uci.CmdGo{Infinite: true})
+		a.mu.Lock()
+		a.updates = nil
+		a.mu.Unlock()
+		close(updates)
+		done <- err
+	}()
+	return updates, nil
+}
+
+// Stop ends an infinite search started with Start and returns its result.
+func (a *Analyzer) Stop() (*Result, error) {
+	a.mu.Lock()
+	done, pos := a.done, a.pos
+	a.mu.Unlock()
+	if done == nil {
+		return nil, errors.New("no analysis running")
+	}
+
+	if err := a.engine.Run(uci.CmdStop); err != nil {
+		return nil, err
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	a.done = nil
+	a.mu.Unlock()
+	return a.result(pos)
+}
+
+func (a *Analyzer) prepare(pos *chess.Position, multiPV int) error {
+	if multiPV < 1 {
+		multiPV = 1
+	}
+	a.mu.Lock()
+	a.pos = pos
+	a.lines = map[int]Line{}
+	a.mu.Unlock()
+
+	return a.engine.Run(
+		uci.CmdSetOption{Name: "MultiPV", Value: strconv.Itoa(multiPV)},
+		uci.CmdIsReady,
+		uci.CmdPosition{Position: pos},
+	)
+}
+
+// result combines the collected lines with the engine's best move.
+func (a *Analyzer) result(pos *chess.Position) (*Result, error) {
+	search := a.engine.SearchResults()
+	if search.BestMove == nil {
+		return nil, errors.New("engine returned no best move")
+	}
+	res := &Result{}
+	var err error
+	if res.BestMove, err = legalMove(pos, search.BestMove); err != nil {
+		return nil, fmt.Errorf("best move: %w", err)
+	}
+	if search.Ponder != nil {
+		res.Ponder, _ = legalMove(pos.Update(res.BestMove), search.Ponder)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := 1; ; i++ {
+		line, ok := a.lines[i]
+		if !ok {
+			break
+		}
+		res.Lines = append(res.Lines, line)
+	}
+	return res, nil
+}
+
+// Write receives the engine's debug log, one line per call.
+func (a *Analyzer) Write(p []byte) (int, error) {
+	text := strings.TrimSpace(string(p))
+	if !strings.HasPrefix(text, "info ") || !strings.Contains(text, " pv ") {
+		return len(p), nil
+	}
+
+	info := &uci.Info{}
+	if err := info.UnmarshalText([]byte(text)); err != nil {
+		return len(p), nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pos == nil {
+		return len(p), nil
+	}
+	line := newLine(a.pos, info)
+	a.lines[line.MultiPV] = line
+	select {
+	case a.updates <- line:
+	default:
+		// The reader fell behind; drop the update rather than stall the
+		// engine. The final result still holds the latest lines.
+	}
+	return len(p), nil
+}
+
+func newLine(pos *chess.Position, info *uci.Info) Line {
+	line := Line{
+		MultiPV:  max(info.Multipv, 1),
+		Depth:    info.Depth,
+		SelDepth: info.Seldepth,
+		Score: Score{
+			CP:         info.Score.CP,
+			Mate:       info.Score.Mate,
+			LowerBound: info.Score.LowerBound,
+			UpperBound: info.Score.UpperBound,
+		},
+		Nodes: info.Nodes,
+		NPS:   info.NPS,
+		Time:  info.Time,
+	}
+	for _, m := range info.PV {
+		legal, err := legalMove(pos, m)
+		if err != nil {
+			line.Truncated = true
+			break
+		}
+		line.PV = append(line.PV, legal)
+		pos = pos.Update(legal)
+	}
+	return line
+}
+
+// legalMove returns the legal move of pos matching m's squares and
+// promotion. Moves decoded from engine output carry no position, so this
+// is what validates them and fills in their tags.
+func legalMove(pos *chess.Position, m *chess.Move) (*chess.Move, error) {
+	for _, legal := range pos.ValidMoves() {
+		if legal.S1() == m.S1() && legal.S2() == m.S2() && legal.Promo() == m.Promo() {
+			return &legal, nil
+		}
+	}
+	return nil, fmt.Errorf("move %s is not legal in %s", m, pos)
+}
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/corentings/chess/v2"
@@ -11,7 +18,10 @@ import (
 
 func main() {
 	fmt.Println("=== UCI Engine Analysis Example ===")
-	
+
+	enginePath := flag.String("engine", "stockfish", "UCI engine to run")
+	flag.Parse()
+
 	// Create a new game
 	game := chess.NewGame()
 
@@ -23,29 +33,177 @@ func main() {
 
 	fmt.Printf("Position to analyze:\n%v\n", game.Position())
 
-	// Initialize UCI engine (assumes Stockfish is in PATH)
-	enginePath := "stockfish"
-	engine, err := uci.New(enginePath)
+	// Initialize UCI engine. When the requested engine is not installed,
+	// fall back to the mock engine from ../mock_engine so the UCI code
+	// paths still run.
+	path := *enginePath
+	usingMock := false
+	if _, err := exec.LookPath(path); err != nil {
+		fmt.Printf("Note: %s not available (%v). Using the mock engine instead.\n", path, err)
+		mockPath, cleanup, err := buildMockEngine()
+		if err != nil {
+			fmt.Printf("Note: could not build the mock engine (%v). Skipping analysis.\n", err)
+			return
+		}
+		defer cleanup()
+		path = mockPath
+		usingMock = true
+	}
+
+	analyzer, err := NewAnalyzer(path)
 	if err != nil {
-		fmt.Printf("Note: Stockfish engine not available (%v). Skipping analysis.\n", err)
+		fmt.Printf("Note: engine not available (%v). Skipping analysis.\n", err)
 		return
 	}
-	defer engine.Close()
+	defer analyzer.Close()
+	fmt.Printf("Engine: %s\n", analyzer.Engine().ID()["name"])
 
-	// Configure analysis
-	analysis := uci.CmdGo{
-		MoveTime: time.Second, // Analyze for 1 second
+	// Example 1: Timed analysis with the three best lines
+	fmt.Println("\n1. Analysis With MultiPV 3")
+	pos := game.Position()
+	result, err := analyzer.Analyze(pos, uci.CmdGo{MoveTime: time.Second}, 3)
+	if err != nil {
+		log.Printf("Engine analysis error: %v\n", err)
+		return
+	}
+	fmt.Printf("Best move: %s\n", chess.AlgebraicNotation{}.Encode(pos, result.BestMove))
+	for _, line := range result.Lines {
+		printLine(pos, line)
 	}
 
-	// Run analysis
-	err = engine.Run(
-		uci.CmdUCI,
-		uci.CmdIsReady,
-		uci.CmdPosition{Position: game.Position()},
-		analysis,
-	)
+	// Example 2: Scores from White's point of view
+	fmt.Println("\n2. Score From White's Point of View")
+	if best, ok := result.Best(); ok {
+		fmt.Printf("Side to move: %v, engine score %v, for White %v\n",
+			pos.Turn(), best.Score, best.Score.ForWhite(pos.Turn()))
+	}
+
+	// Example 3: Streaming infinite analysis
+	fmt.Println("\n3. Streaming Infinite Analysis")
+	updates, err := analyzer.Start(pos, 1)
 	if err != nil {
 		log.Printf("Engine analysis error: %v\n", err)
 		return
 	}
+	stop := time.After(500 * time.Millisecond)
+	for updates != nil {
+		select {
+		case line, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			printLine(pos, line)
+		case <-stop:
+			stop = nil
+			result, err := analyzer.Stop()
+			if err != nil {
+				log.Printf("Engine analysis error: %v\n", err)
+				return
+			}
+			fmt.Printf("Stopped. Best move: %s\n", chess.AlgebraicNotation{}.Encode(pos, result.BestMove))
+			updates = nil
+		}
+	}
+
+	if usingMock {
+		runFailureScenarios(path, game.Position())
+	}
+}
+
+// runFailureScenarios drives the mock engine through crashes, hangs and
+// malformed output, showing how a caller can survive each of them.
+func runFailureScenarios(enginePath string, pos *chess.Position) {
+	fmt.Println("\nFailure Scenarios (mock engine)")
+	scenarios := []struct {
+		name   string
+		script string
+	}{
+		{"Engine crashes during search", "crash go"},
+		{"Engine stops answering isready", "hang isready"},
+		{"Engine sends malformed output", "malformed go"},
+	}
+
+	for _, sc := range scenarios {
+		scriptPath := filepath.Join(os.TempDir(), "uci_analysis_mock.txt")
+		if err := os.WriteFile(scriptPath, []byte(sc.script+"\n"), 0o644); err != nil {
+			log.Printf("Error writing mock script: %v\n", err)
+			return
+		}
+
+		engine, err := uci.New(enginePath)
+		if err != nil {
+			log.Printf("Error starting mock engine: %v\n", err)
+			continue
+		}
+		err = runWithTimeout(engine, 2*time.Second,
+			uci.CmdUCI,
+			uci.CmdSetOption{Name: "Script", Value: scriptPath},
+			uci.CmdIsReady,
+			uci.CmdPosition{Position: pos},
+			uci.CmdGo{MoveTime: 100 * time.Millisecond},
+		)
+		fmt.Printf("  %-32s -> %v\n", sc.name+":", err)
+		os.Remove(scriptPath)
+	}
+}
+
+var errEngineTimeout = errors.New("engine did not answer in time")
+
+// runWithTimeout runs cmds on the engine and gives up after timeout. A
+// crashed engine looks the same as a hung one to the uci package (its
+// output pipe is never closed), so the timeout is the only way to notice
+// either. On timeout the engine process is killed; the engine must not be
+// used (or closed) afterwards because its command is still blocked.
+func runWithTimeout(engine *uci.Engine, timeout time.Duration, cmds ...uci.Cmd) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.Run(cmds...)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			engine.Close()
+			return err
+		}
+		if engine.SearchResults().BestMove == nil {
+			engine.Close()
+			return errors.New("engine returned no best move")
+		}
+		return engine.Close()
+	case <-time.After(timeout):
+		if p, err := os.FindProcess(engine.Getpid()); err == nil {
+			p.Kill()
+		}
+		return errEngineTimeout
+	}
+}
+
+// buildMockEngine compiles ../mock_engine into a temporary directory and
+// returns the path of the binary and a function removing it.
+func buildMockEngine() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mock_engine")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	bin := filepath.Join(dir, "mock_engine")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", bin, ".")
+	cmd.Dir = filepath.Join("..", "mock_engine")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return bin, cleanup, nil
+}
+
+func printLine(pos *chess.Position, line Line) {
+	fmt.Printf("  #%d depth %2d/%-2d score %6v nodes %8d nps %8d  %s\n",
+		line.MultiPV, line.Depth, line.SelDepth, line.Score, line.Nodes, line.NPS,
+		strings.Join(line.SAN(pos), " "))
 }